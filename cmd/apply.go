@@ -1,21 +1,50 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/diff"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
 )
 
 var applyCmd = &cobra.Command{
 	Use:   "apply",
 	Short: "Apply a configuration to a resource",
-	Long:  "Apply a configuration to a resource by file name or stdin. The resource name must be specified.",
-	RunE:  runApply,
+	Long: `Apply a configuration to a resource by file name or stdin. The resource name must be specified.
+
+Before applying, kcsi computes a server-side diff of every object in the
+manifest or kustomization and shows which objects will be created, changed
+or left unchanged. The apply then requires confirmation (or --yes).
+On a protected context the apply is refused without --yes.`,
+	RunE: runApply,
+}
+
+// applyObjectState describes what applying a manifest does to a single object
+type applyObjectState string
+
+const (
+	applyCreated   applyObjectState = "created"
+	applyChanged   applyObjectState = "changed"
+	applyUnchanged applyObjectState = "unchanged"
+)
+
+// applyPreview is the server-side diff result for a single object
+type applyPreview struct {
+	Kind      string
+	Name      string
+	Namespace string
+	State     applyObjectState
+	Changes   []diff.Change
 }
 
+// maxPreviewFields limits the number of changed fields listed per object
+const maxPreviewFields = 10
+
 func init() {
 	rootCmd.AddCommand(applyCmd)
 
@@ -28,6 +57,7 @@ func init() {
 	applyCmd.Flags().StringP("output", "o", "", FlagDescOutput)
 	applyCmd.Flags().Bool("recursive", false, "Process the directory used in -f, --filename recursively")
 	applyCmd.Flags().StringSliceP("kustomize", "k", []string{}, "Process a kustomization directory")
+	applyCmd.Flags().BoolP("yes", "y", false, FlagDescSkipConfirm)
 
 	applyCmd.RegisterFlagCompletionFunc("namespace", func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 		namespaces, err := kubernetes.GetNamespaces()
@@ -52,6 +82,7 @@ func runApply(cmd *cobra.Command, _ []string) error {
 	output, _ := cmd.Flags().GetString("output")
 	recursive, _ := cmd.Flags().GetBool("recursive")
 	kustomize, _ := cmd.Flags().GetStringSlice("kustomize")
+	yes, _ := cmd.Flags().GetBool("yes")
 
 	args := []string{"apply"}
 
@@ -60,6 +91,17 @@ func runApply(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// Dry runs don't change the cluster, so they skip the preview
+	if !dryRun && !serverDryRun {
+		proceed, err := previewAndConfirmApply(args, namespace, validate, yes)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			return nil
+		}
+	}
+
 	// Add optional flags
 	addApplyFlags(&args, namespace, serverDryRun, dryRun, validate, force, output)
 
@@ -152,3 +194,182 @@ func addApplyFlags(args *[]string, namespace string, serverDryRun, dryRun, valid
 		*args = append(*args, "-o", output)
 	}
 }
+
+// previewAndConfirmApply shows the server-side diff and asks for confirmation.
+// It returns false if there is nothing to apply or the user declined.
+func previewAndConfirmApply(sourceArgs []string, namespace string, validate, yes bool) (bool, error) {
+	fmt.Println("🔍 Computing server-side diff...")
+	fmt.Println()
+
+	previews, err := computeApplyPreview(sourceArgs, namespace, validate)
+	if err != nil {
+		return false, err
+	}
+
+	printApplyPreview(previews)
+
+	if !applyHasChanges(previews) {
+		fmt.Println("✅ Nothing to apply, all objects are unchanged")
+		return false, nil
+	}
+
+	proceed, err := confirmClusterChange("Apply these changes?", yes)
+	if err != nil {
+		return false, err
+	}
+	if !proceed {
+		fmt.Println("Apply cancelled.")
+		return false, nil
+	}
+
+	fmt.Println()
+	return true, nil
+}
+
+// computeApplyPreview runs a server-side dry-run apply and compares every
+// resulting object with its live state
+func computeApplyPreview(sourceArgs []string, namespace string, validate bool) ([]applyPreview, error) {
+	args := append([]string{}, sourceArgs...)
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	if !validate {
+		args = append(args, "--validate=false")
+	}
+	args = append(args, "--dry-run=server", "-o", "json")
+
+	output, err := kubernetes.ExecuteKubectl(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute server-side diff: %v", err)
+	}
+
+	objects, err := parseAppliedObjects(output)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]applyPreview, 0, len(objects))
+	for _, obj := range objects {
+		preview, err := previewObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, preview)
+	}
+
+	return previews, nil
+}
+
+// parseAppliedObjects returns the objects of a dry-run result, which is
+// either a single object or a List
+func parseAppliedObjects(output string) ([]map[string]interface{}, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse dry-run output: %v", err)
+	}
+
+	items, isList := obj["items"].([]interface{})
+	if !isList {
+		return []map[string]interface{}{obj}, nil
+	}
+
+	objects := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if itemObj, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, itemObj)
+		}
+	}
+	return objects, nil
+}
+
+// previewObject compares a dry-run object with the live object in the cluster
+func previewObject(obj map[string]interface{}) (applyPreview, error) {
+	kind, _ := obj["kind"].(string)
+	apiVersion, _ := obj["apiVersion"].(string)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	preview := applyPreview{Kind: kind, Name: name, Namespace: namespace}
+
+	live, err := kubernetes.GetObject(qualifiedResource(kind, apiVersion), name, namespace)
+	if err != nil {
+		return preview, fmt.Errorf("failed to get %s/%s: %v", kind, name, err)
+	}
+
+	if live == nil {
+		preview.State = applyCreated
+		return preview, nil
+	}
+
+	preview.Changes = diff.Objects(diff.StripNoise(live), diff.StripNoise(obj))
+	if len(preview.Changes) == 0 {
+		preview.State = applyUnchanged
+	} else {
+		preview.State = applyChanged
+	}
+	return preview, nil
+}
+
+// qualifiedResource returns kind.group so that kubectl resolves the right API group
+func qualifiedResource(kind, apiVersion string) string {
+	if group, _, found := strings.Cut(apiVersion, "/"); found {
+		return kind + "." + group
+	}
+	return kind
+}
+
+func applyHasChanges(previews []applyPreview) bool {
+	for _, p := range previews {
+		if p.State != applyUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// printApplyPreview renders the colored per-object diff summary
+func printApplyPreview(previews []applyPreview) {
+	counts := map[applyObjectState]int{}
+
+	for _, p := range previews {
+		counts[p.State]++
+
+		ref := fmt.Sprintf("%s/%s", p.Kind, p.Name)
+		if p.Namespace != "" {
+			ref = fmt.Sprintf("%s (namespace: %s)", ref, p.Namespace)
+		}
+
+		switch p.State {
+		case applyCreated:
+			fmt.Printf("  %s %s\n", colorize(colorGreen, "+ created  "), ref)
+		case applyChanged:
+			fmt.Printf("  %s %s\n", colorize(colorYellow, "~ changed  "), ref)
+			printPreviewChanges(p.Changes)
+		default:
+			fmt.Printf("  %s %s\n", colorize(colorGray, "= unchanged"), ref)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Summary: %d to create, %d to change, %d unchanged\n",
+		counts[applyCreated], counts[applyChanged], counts[applyUnchanged])
+	fmt.Println()
+}
+
+func printPreviewChanges(changes []diff.Change) {
+	for i, change := range changes {
+		if i == maxPreviewFields {
+			fmt.Printf("      ... and %d more field(s)\n", len(changes)-maxPreviewFields)
+			return
+		}
+		switch change.Type {
+		case diff.Added:
+			fmt.Printf("      %s\n", colorize(colorGreen, "+ "+change.Path))
+		case diff.Removed:
+			fmt.Printf("      %s\n", colorize(colorRed, "- "+change.Path))
+		default:
+			fmt.Printf("      %s\n", colorize(colorYellow, "~ "+change.Path))
+		}
+	}
+}
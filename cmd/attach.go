@@ -1,10 +1,21 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/attach"
 	"github.com/stanzinofree/kcsi/pkg/completion"
+	kcsicontext "github.com/stanzinofree/kcsi/pkg/context"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
 )
 
@@ -13,11 +24,21 @@ var attachCmd = &cobra.Command{
 	Short: "Attach to a pod with an interactive shell",
 	Long: `Attach to a pod and start an interactive shell session.
 Use -n to specify namespace first for better autocompletion.
-Tries bash, zsh, and sh in order to find an available shell.
+
+The available shells (bash, zsh, ash, sh in order of preference) are
+detected first with a quick non-interactive probe, and the shell that
+worked is remembered per image in ~/.kcsi/shells.yaml so later sessions
+start right away. Exiting the shell with a non-zero status ends the
+session normally.
+
+With --record the session transcript (everything shown in the terminal,
+including the commands typed) is written to ~/.kcsi/sessions for audit
+purposes. View it with "less -R".
 
 Examples:
   kcsi attach -n production my-pod
-  kcsi attach -n production my-pod -c sidecar`,
+  kcsi attach -n production my-pod -c sidecar
+  kcsi attach -n production my-pod --record`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runAttach,
 	ValidArgsFunction: completion.PodCompletion,
@@ -26,43 +47,161 @@ Examples:
 var (
 	attachNamespace string
 	attachContainer string
+	attachRecord    bool
 )
 
 func runAttach(_ *cobra.Command, args []string) error {
 	podName := args[0]
 
-	// Shells to try in order of preference
-	shells := []string{"bash", "zsh", "sh"}
+	explicitNamespace := kubernetes.InjectDefaultNamespace(attachNamespace) != ""
+	namespace := kubernetes.ResolveNamespace(attachNamespace)
 
-	for _, shell := range shells {
-		// Build kubectl command with namespace injection
-		kubectlArgs := kubernetes.BuildNamespaceArgs([]string{"exec", "-it"}, attachNamespace)
-		kubectlArgs = append(kubectlArgs, podName)
-
-		if attachContainer != "" {
-			kubectlArgs = append(kubectlArgs, "-c", attachContainer)
+	pod, err := kubernetes.GetPod(namespace, podName)
+	if err != nil {
+		if !explicitNamespace {
+			return fmt.Errorf("failed to get pod %s: %v\nHint: Did you forget to specify the namespace with -n?", podName, err)
+		}
+		return fmt.Errorf("failed to get pod %s: %v", podName, err)
+	}
+	container, ok := pod.Container(attachContainer)
+	if !ok {
+		var names []string
+		for _, c := range pod.Spec.Containers {
+			names = append(names, c.Name)
 		}
+		return fmt.Errorf("container '%s' not found in pod %s (available: %s)", attachContainer, podName, strings.Join(names, ", "))
+	}
+
+	shell, err := detectShell(namespace, podName, container)
+	if err != nil {
+		return err
+	}
 
-		kubectlArgs = append(kubectlArgs, "--", shell)
+	fmt.Printf("Attaching to %s (container %s) with %s...\n", podName, container.Name, shell)
+	if attachRecord {
+		return runRecordedSession(namespace, podName, container, shell)
+	}
+
+	kubectlArgs := []string{"exec", "-it", "-n", namespace, podName, "-c", container.Name, "--", shell}
+	return endSession(runKubectlTerminal(kubectlArgs, os.Stdout))
+}
 
-		fmt.Printf("Trying to attach with %s...\n", shell)
+// detectShell probes the shells of a container without a terminal, starting
+// with the one remembered for its image, and remembers the one that works
+func detectShell(namespace, podName string, container kubernetes.Container) (string, error) {
+	var cache *attach.ShellCache
+	cachePath := ""
+	if kcsiDir, err := kcsicontext.GetKcsiDir(); err == nil {
+		cachePath = attach.CachePath(kcsiDir)
+		cache, _ = attach.LoadShellCache(cachePath)
+	}
+	known := ""
+	if cache != nil {
+		known = cache.Images[container.Image]
+	}
 
-		err := kubernetes.ExecuteKubectlInteractive(kubectlArgs...)
+	for _, shell := range attach.ShellOrder(known) {
+		probeArgs := append([]string{"exec", "-n", namespace, podName, "-c", container.Name, "--"}, attach.ProbeCommand(shell)...)
+		output, err := kubernetes.KubectlCommand(context.Background(), probeArgs...).CombinedOutput()
 		if err == nil {
-			// Successfully attached
-			return nil
+			if cache != nil && known != shell {
+				cache.Images[container.Image] = shell
+				if err := cache.Save(cachePath); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Could not remember the shell: %v\n", err)
+				}
+			}
+			return shell, nil
+		}
+
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		if !attach.IsShellMissing(string(output), exitCode) {
+			return "", fmt.Errorf("failed to exec into pod %s: %s", podName, strings.TrimSpace(string(output)))
 		}
+		fmt.Println(colorize(colorGray, shell+" not available"))
+	}
+
+	return "", fmt.Errorf("no interactive shell found in pod %s (tried %s)\nTry kcsi debug for images without a shell", podName, strings.Join(attach.Shells, ", "))
+}
+
+// runRecordedSession attaches with the terminal output copied to a
+// transcript file under ~/.kcsi/sessions
+func runRecordedSession(namespace, podName string, container kubernetes.Container, shell string) error {
+	kcsiDir, err := kcsicontext.GetKcsiDir()
+	if err != nil {
+		return err
+	}
+	contextName, _ := kcsicontext.GetCurrentContextName()
+	started := time.Now()
+	path := attach.SessionPath(kcsiDir, contextName, namespace, podName, started)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	transcript, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create session transcript: %w", err)
+	}
+	defer transcript.Close()
 
-		// If this shell didn't work, try the next one
-		fmt.Printf("%s not available, trying next shell...\n", shell)
+	username := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		username = current.Username
 	}
+	fmt.Fprint(transcript, attach.SessionHeader(started, username, contextName, namespace, podName, container.Name, container.Image, shell))
+	fmt.Println(colorize(colorGray, "Recording session to "+path))
+
+	// kubectl cannot read the terminal size from a piped output, so the
+	// remote terminal is sized once at the start
+	rows, cols := terminalSize()
+	kubectlArgs := append([]string{"exec", "-it", "-n", namespace, podName, "-c", container.Name, "--"}, attach.SessionCommand(shell, rows, cols)...)
+	err = runKubectlTerminal(kubectlArgs, io.MultiWriter(os.Stdout, transcript))
+
+	fmt.Fprintf(transcript, "\n# session ended %s\n", time.Now().Format(time.RFC3339))
+	fmt.Println(colorize(colorGray, "Session recorded to "+path))
+	return endSession(err)
+}
+
+// runKubectlTerminal runs kubectl on the terminal with its output copied to out
+func runKubectlTerminal(args []string, out io.Writer) error {
+	cmd := kubernetes.KubectlCommand(context.Background(), args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// endSession treats a shell exiting with a non-zero status as the normal
+// end of the session
+func endSession(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		fmt.Println(colorize(colorGray, fmt.Sprintf("Session ended (exit code %d)", exitErr.ExitCode())))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("kubectl error: %v", err)
+	}
+	return nil
+}
 
-	// Provide helpful error message
-	effectiveNS := kubernetes.InjectDefaultNamespace(attachNamespace)
-	if effectiveNS == "" {
-		return fmt.Errorf("no interactive shell found in pod %s\nHint: Did you forget to specify the namespace with -n?", podName)
+// terminalSize returns the rows and columns of the local terminal, or zeros
+// when unknown
+func terminalSize() (int, int) {
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0
+	}
+	var rows, cols int
+	if _, err := fmt.Sscanf(string(output), "%d %d", &rows, &cols); err != nil {
+		return 0, 0
 	}
-	return fmt.Errorf("no interactive shell found in pod %s", podName)
+	return rows, cols
 }
 
 func init() {
@@ -70,6 +209,7 @@ func init() {
 
 	attachCmd.Flags().StringVarP(&attachNamespace, "namespace", "n", "", FlagDescNamespace)
 	attachCmd.Flags().StringVarP(&attachContainer, "container", "c", "", "Container name (for multi-container pods)")
+	attachCmd.Flags().BoolVar(&attachRecord, "record", false, "Record the session transcript to ~/.kcsi/sessions")
 
 	attachCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
 	attachCmd.RegisterFlagCompletionFunc("container", completion.ContainerCompletion)
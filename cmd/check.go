@@ -2,85 +2,224 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/checks"
+	"github.com/stanzinofree/kcsi/pkg/completion"
+	"github.com/stanzinofree/kcsi/pkg/diagnose"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"github.com/stanzinofree/kcsi/pkg/version"
 )
 
 var checkCmd = &cobra.Command{
-	Use:   "check",
+	Use:   "check [check-id...]",
 	Short: "Check cluster health and issues",
-	Long:  `Run various health checks on the cluster`,
+	Long: `Run various health checks on the cluster.
+
+Run every registered check with 'kcsi check all', or selected checks by ID.
+List the available checks with 'kcsi check list'.
+
+Examples:
+  kcsi check all
+  kcsi check all -n production
+  kcsi check node-conditions unbound-pvcs
+  kcsi check errors`,
+	Args:              cobra.ArbitraryArgs,
+	RunE:              runCheckByID,
+	ValidArgsFunction: checkIDCompletion,
 }
 
 var checkErrorsCmd = &cobra.Command{
 	Use:     "errors",
 	Aliases: []string{"err", "error"},
 	Short:   "Find pods with errors",
-	Long:    `Find all pods that are not in Running or Completed state (errors, crashloops, pending, etc.)`,
-	RunE:    runCheckErrors,
+	Long: `Find unhealthy pods and group them by category:
+
+  crashlooping           containers in CrashLoopBackOff
+  image-pull             images that cannot be pulled
+  pending-unschedulable  pods the scheduler cannot place
+  evicted                pods evicted by the kubelet
+  failed                 pods that failed for another reason
+  not-ready              running pods that are not ready
+  high-restarts          containers restarted at least --restart-threshold times
+
+Without -n, the default namespace of the current context is checked, or all
+namespaces when none is set.
+
+Examples:
+  kcsi check errors
+  kcsi check errors -n production
+  kcsi check errors -A --category crashlooping,image-pull
+  kcsi check errors -o junit > pod-health.xml`,
+	RunE: runCheckErrors,
 }
 
+var (
+	checkNamespace        string
+	checkAllNamespaces    bool
+	checkCategories       []string
+	checkRestartThreshold int
+)
+
 func init() {
 	rootCmd.AddCommand(checkCmd)
 	checkCmd.AddCommand(checkErrorsCmd)
+
+	checkCmd.PersistentFlags().StringVarP(&checkNamespace, "namespace", "n", "", "Kubernetes namespace (default namespace or all namespaces if not specified)")
+	checkCmd.PersistentFlags().BoolVarP(&checkAllNamespaces, "all-namespaces", "A", false, "Check all namespaces, ignoring the default namespace")
+	checkCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
+
+	checkErrorsCmd.Flags().StringSliceVar(&checkCategories, "category", nil, "Only report these categories (comma separated)")
+	checkErrorsCmd.Flags().IntVar(&checkRestartThreshold, "restart-threshold", 5, "Restarts from which a container is reported as high-restarts (0 to disable)")
+	checkErrorsCmd.RegisterFlagCompletionFunc("category", checkCategoryCompletion)
 }
 
-func runCheckErrors(_ *cobra.Command, _ []string) error {
-	fmt.Println("Checking for pods with errors across all namespaces...")
-	fmt.Println("(Excluding: Running, Completed)")
-	fmt.Println()
+// podProblemRow is a problem of a specific pod
+type podProblemRow struct {
+	Namespace string
+	Pod       string
+	Problem   diagnose.PodProblem
+}
+
+// checkScope returns the namespace to check, empty for all namespaces
+func checkScope() string {
+	if checkAllNamespaces {
+		return ""
+	}
+	return kubernetes.InjectDefaultNamespace(checkNamespace)
+}
 
-	// Get all pods with wide output
-	output, err := kubernetes.ExecuteKubectl("get", "pods", flagAllNamespaces, "-o", "wide")
+// runCheckErrors reports unhealthy pods as a table, or as a report of the
+// pod-health check with -o, exiting like the other checks
+func runCheckErrors(cmd *cobra.Command, _ []string) error {
+	wanted, err := parseCheckCategories(checkCategories)
+	if err != nil {
+		return err
+	}
+	failOn, failEnabled, err := parseCheckReportFlags()
 	if err != nil {
-		return fmt.Errorf("failed to execute kubectl: %w", err)
+		return err
 	}
+	podHealth, _ := checks.Get("pod-health")
 
-	lines := strings.Split(string(output), "\n")
-	if len(lines) == 0 {
-		fmt.Println("No pods found.")
-		return nil
+	namespace := checkScope()
+	if checkOutput == "text" {
+		if namespace == "" {
+			fmt.Println("Checking pod health across all namespaces...")
+		} else {
+			fmt.Printf("Checking pod health in namespace %s...\n", namespace)
+		}
+		fmt.Println()
 	}
 
-	// Print header
-	header := lines[0]
-	fmt.Println(header)
+	pods, err := kubernetes.ListPods(namespace, "")
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
 
-	// Filter and print non-running/non-completed pods
-	foundIssues := false
-	for _, line := range lines[1:] {
-		if line == "" {
-			continue
+	grouped := map[diagnose.Category][]podProblemRow{}
+	affected := map[string]bool{}
+	var findings []checks.Finding
+	for _, pod := range pods {
+		for _, problem := range diagnose.PodProblems(pod, checkRestartThreshold) {
+			if !wanted[problem.Category] {
+				continue
+			}
+			grouped[problem.Category] = append(grouped[problem.Category], podProblemRow{
+				Namespace: pod.Metadata.Namespace,
+				Pod:       pod.Metadata.Name,
+				Problem:   problem,
+			})
+			affected[pod.Metadata.Namespace+"/"+pod.Metadata.Name] = true
+			findings = append(findings, checks.PodProblemFinding(pod, problem))
 		}
+	}
 
-		// Check if line contains Running or Completed (case insensitive)
-		lowerLine := strings.ToLower(line)
-		if strings.Contains(lowerLine, "running") || strings.Contains(lowerLine, "completed") {
-			continue
+	report := checks.Report{
+		Namespace:   namespace,
+		GeneratedAt: time.Now(),
+		ToolVersion: version.GetVersion(),
+		Results:     []checks.Result{checks.NewResult(podHealth, findings)},
+	}
+	if checkOutput != "text" {
+		if err := report.Write(os.Stdout, checkOutput); err != nil {
+			return err
 		}
+		return checkFailure(cmd, report, failOn, failEnabled)
+	}
 
-		// Print problematic pod
-		fmt.Println(line)
-		foundIssues = true
+	if len(affected) == 0 {
+		fmt.Printf("✓ No problematic pods found among %d pods.\n", len(pods))
+		return nil
 	}
 
-	if !foundIssues {
-		fmt.Println()
-		fmt.Println("✓ No problematic pods found! All pods are Running or Completed.")
-	} else {
-		fmt.Println()
-		fmt.Println("⚠ Found pods with issues. Common states to investigate:")
-		fmt.Println("  - CrashLoopBackOff: Pod is repeatedly crashing")
-		fmt.Println("  - Error: Pod encountered an error")
-		fmt.Println("  - Pending: Pod cannot be scheduled")
-		fmt.Println("  - ImagePullBackOff: Cannot pull container image")
-		fmt.Println("  - CreateContainerError: Container creation failed")
+	for _, category := range diagnose.Categories {
+		rows := grouped[category]
+		if len(rows) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s %s\n", colorize(colorRed, "●"), colorize(colorBold, fmt.Sprintf("%s (%d)", category, len(rows))))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "  NAMESPACE\tPOD\tCONTAINER\tREASON\tDETAILS")
+		for _, row := range rows {
+			container := row.Problem.Container
+			if container == "" {
+				container = "-"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", row.Namespace, row.Pod, container, row.Problem.Reason, truncateMessage(row.Problem.Message, 80))
+		}
+		w.Flush()
 		fmt.Println()
-		fmt.Println("Use 'kcsi logs -n <namespace> <pod>' to investigate further")
-		fmt.Println("Use 'kcsi describe pod -n <namespace> <pod>' for detailed information")
 	}
 
-	return nil
+	fmt.Printf("⚠ Found %d of %d pods with issues.\n", len(affected), len(pods))
+	fmt.Println("Use 'kcsi why -n <namespace> <pod>' to find the likely cause")
+	fmt.Println("Use 'kcsi logs -n <namespace> <pod>' to investigate further")
+
+	return checkFailure(cmd, report, failOn, failEnabled)
+}
+
+// parseCheckCategories validates --category values; no values selects every category
+func parseCheckCategories(values []string) (map[diagnose.Category]bool, error) {
+	wanted := map[diagnose.Category]bool{}
+	for _, category := range diagnose.Categories {
+		wanted[category] = len(values) == 0
+	}
+
+	for _, value := range values {
+		category := diagnose.Category(strings.TrimSpace(value))
+		if _, ok := wanted[category]; !ok {
+			return nil, fmt.Errorf("unknown category '%s' (valid: %s)", value, strings.Join(categoryNames(), ", "))
+		}
+		wanted[category] = true
+	}
+	return wanted, nil
+}
+
+func categoryNames() []string {
+	names := make([]string, len(diagnose.Categories))
+	for i, c := range diagnose.Categories {
+		names[i] = string(c)
+	}
+	return names
+}
+
+func checkCategoryCompletion(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return categoryNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// truncateMessage shortens a message to fit on a single table line
+func truncateMessage(message string, max int) string {
+	message = strings.Join(strings.Fields(message), " ")
+	runes := []rune(message)
+	if len(runes) <= max {
+		return message
+	}
+	// Cut on runes so multi-byte characters in kubelet messages stay valid
+	return string(runes[:max-3]) + "..."
 }
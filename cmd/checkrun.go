@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/checks"
+	"github.com/stanzinofree/kcsi/pkg/version"
+)
+
+var checkAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Run every registered cluster check",
+	Long: `Run every registered cluster check and report the findings grouped by check.
+
+Examples:
+  kcsi check all
+  kcsi check all -n production`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return runChecks(cmd, checks.All())
+	},
+}
+
+var checkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available cluster checks",
+	Args:  cobra.NoArgs,
+	RunE:  runCheckList,
+}
+
+var (
+	checkOutput          string
+	checkFailOn          string
+	checkBaseline        string
+	checkWriteBaseline   bool
+	checkBaselineReason  string
+	checkBaselineExpires string
+)
+
+func init() {
+	checkCmd.AddCommand(checkAllCmd)
+	checkCmd.AddCommand(checkListCmd)
+
+	for _, cmd := range []*cobra.Command{checkCmd, checkAllCmd, checkErrorsCmd} {
+		cmd.Flags().StringVarP(&checkOutput, "output", "o", "text", "Output format: text, "+strings.Join(checks.Formats, ", "))
+		cmd.Flags().StringVar(&checkFailOn, "fail-on", "none", "Exit with the code of the highest severity when a finding has at least this severity (info, warning, error, none)")
+		cmd.RegisterFlagCompletionFunc("output", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return append([]string{"text"}, checks.Formats...), cobra.ShellCompDirectiveNoFileComp
+		})
+		cmd.RegisterFlagCompletionFunc("fail-on", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{"info", "warning", "error", "none"}, cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+	for _, cmd := range []*cobra.Command{checkCmd, checkAllCmd} {
+		cmd.Flags().StringVar(&checkBaseline, "baseline", checks.DefaultBaselineFile, "Baseline file with suppressed findings (empty to disable)")
+		cmd.Flags().BoolVar(&checkWriteBaseline, "write-baseline", false, "Add the current findings to the baseline file instead of reporting them")
+		cmd.Flags().StringVar(&checkBaselineReason, "baseline-reason", "", "Reason recorded for suppressions added by --write-baseline")
+		cmd.Flags().StringVar(&checkBaselineExpires, "baseline-expires", "", "Expiry date (YYYY-MM-DD) of suppressions added by --write-baseline")
+	}
+}
+
+func runCheckByID(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		if !checkWriteBaseline {
+			return cmd.Help()
+		}
+		// Snapshotting the baseline covers every check by default
+		args = []string{"all"}
+	}
+
+	selected, err := checks.Select(args)
+	if err != nil {
+		return err
+	}
+	return runChecks(cmd, selected)
+}
+
+func runCheckList(_ *cobra.Command, _ []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tSEVERITY\tDESCRIPTION")
+	for _, check := range checks.All() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", check.ID, check.Severity, check.Description)
+	}
+	return w.Flush()
+}
+
+// runChecks loads the cluster state needed by the checks, runs them and
+// reports the findings. The returned error carries the exit code of the
+// highest severity found when it reaches --fail-on.
+func runChecks(cmd *cobra.Command, selected []checks.Check) error {
+	failOn, failEnabled, err := parseCheckReportFlags()
+	if err != nil {
+		return err
+	}
+
+	if checkBaselineExpires != "" {
+		if _, err := time.Parse("2006-01-02", checkBaselineExpires); err != nil {
+			return fmt.Errorf("invalid --baseline-expires '%s', expected YYYY-MM-DD", checkBaselineExpires)
+		}
+	}
+
+	baseline := &checks.Baseline{}
+	if checkBaseline != "" {
+		if baseline, err = checks.LoadBaseline(checkBaseline); err != nil {
+			return err
+		}
+	}
+
+	namespace := checkScope()
+
+	state, err := checks.LoadState(namespace, selected)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	results := checks.Run(selected, state)
+
+	if checkWriteBaseline {
+		return writeCheckBaseline(baseline, results, now)
+	}
+
+	results, suppressed, expired := baseline.Apply(results, now)
+	for _, s := range expired {
+		fmt.Fprintf(os.Stderr, "⚠️  Baseline suppression for %s expired on %s, its findings are reported again\n", describeSuppression(s), s.Expires)
+	}
+
+	report := checks.Report{
+		Namespace:   namespace,
+		GeneratedAt: now,
+		ToolVersion: version.GetVersion(),
+		Results:     results,
+		Suppressed:  suppressed,
+	}
+
+	if checkOutput == "text" {
+		printCheckResults(report, namespace)
+	} else if err := report.Write(os.Stdout, checkOutput); err != nil {
+		return err
+	}
+
+	return checkFailure(cmd, report, failOn, failEnabled)
+}
+
+// parseCheckReportFlags validates --output and --fail-on
+func parseCheckReportFlags() (checks.Severity, bool, error) {
+	if checkOutput != "text" && !isCheckFormat(checkOutput) {
+		return checks.SeverityInfo, false, fmt.Errorf("unknown output format '%s' (valid: text, %s)", checkOutput, strings.Join(checks.Formats, ", "))
+	}
+	return parseFailOn(checkFailOn)
+}
+
+// checkFailure returns an error carrying the exit code of the highest
+// severity found (see Severity.ExitCode) when it reaches --fail-on
+func checkFailure(cmd *cobra.Command, report checks.Report, failOn checks.Severity, failEnabled bool) error {
+	highest, found := report.Highest()
+	if !failEnabled || !found || highest < failOn {
+		return nil
+	}
+
+	// Findings are reported above, usage help would only add noise
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return &exitCodeError{
+		code:    highest.ExitCode(),
+		message: fmt.Sprintf("checks failed: highest severity %s (--fail-on %s)", highest, checkFailOn),
+	}
+}
+
+// writeCheckBaseline adds the current findings to the baseline file so that
+// only new findings are reported by later runs
+func writeCheckBaseline(baseline *checks.Baseline, results []checks.Result, now time.Time) error {
+	if checkBaseline == "" {
+		return fmt.Errorf("--write-baseline requires a --baseline file")
+	}
+
+	reason := checkBaselineReason
+	if reason == "" {
+		reason = "Accepted in baseline on " + now.Format("2006-01-02")
+	}
+
+	added := baseline.Add(results, reason, checkBaselineExpires, now)
+	if err := baseline.Save(checkBaseline); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Baseline %s written: %d new suppression(s), %d in total\n", checkBaseline, added, len(baseline.Suppressions))
+	return nil
+}
+
+// describeSuppression renders the scope of a suppression
+func describeSuppression(s checks.Suppression) string {
+	scope := []string{s.Check}
+	for _, part := range []string{s.Kind, s.Namespace, s.Name} {
+		if part != "" {
+			scope = append(scope, part)
+		}
+	}
+	return strings.Join(scope, " ")
+}
+
+// parseFailOn parses --fail-on; "none" disables failing on findings
+func parseFailOn(value string) (checks.Severity, bool, error) {
+	if value == "none" {
+		return checks.SeverityInfo, false, nil
+	}
+	severity, err := checks.ParseSeverity(value)
+	if err != nil {
+		return severity, false, fmt.Errorf("invalid --fail-on: %v", err)
+	}
+	return severity, true, nil
+}
+
+func isCheckFormat(format string) bool {
+	for _, f := range checks.Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func printCheckResults(report checks.Report, namespace string) {
+	results := report.Results
+	if namespace == "" {
+		fmt.Println("Running cluster checks across all namespaces...")
+	} else {
+		fmt.Printf("Running cluster checks in namespace %s...\n", namespace)
+	}
+	fmt.Println()
+
+	passed := 0
+	counts := map[checks.Severity]int{}
+	for _, result := range results {
+		if len(result.Findings) == 0 {
+			passed++
+			fmt.Printf("%s %s %s\n", colorize(colorGreen, "✓"), result.Check.ID, colorize(colorGray, "- "+result.Check.Description))
+			continue
+		}
+
+		counts[result.Check.Severity] += len(result.Findings)
+		fmt.Printf("%s %s %s %s\n",
+			colorize(severityColor(result.Check.Severity), "●"),
+			colorize(colorBold, result.Check.ID),
+			colorize(severityColor(result.Check.Severity), fmt.Sprintf("[%s]", result.Check.Severity)),
+			colorize(colorGray, fmt.Sprintf("- %s (%d)", result.Check.Description, len(result.Findings))))
+		for _, finding := range result.Findings {
+			fmt.Printf("    %s: %s\n", finding.Resource, finding.Message)
+		}
+	}
+
+	fmt.Println()
+	if report.Suppressed > 0 {
+		fmt.Printf("ℹ️  %d finding(s) suppressed by baseline %s\n", report.Suppressed, checkBaseline)
+	}
+	var summary []string
+	for _, severity := range []checks.Severity{checks.SeverityError, checks.SeverityWarning, checks.SeverityInfo} {
+		if counts[severity] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	if len(summary) == 0 {
+		fmt.Printf("✓ All %d checks passed\n", len(results))
+		return
+	}
+	fmt.Printf("Summary: %d of %d checks passed, findings: %s\n", passed, len(results), strings.Join(summary, ", "))
+}
+
+func severityColor(severity checks.Severity) string {
+	switch severity {
+	case checks.SeverityError:
+		return colorRed
+	case checks.SeverityWarning:
+		return colorYellow
+	}
+	return colorBlue
+}
+
+// checkIDCompletion completes check IDs for 'kcsi check <id>'
+func checkIDCompletion(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	used := map[string]bool{}
+	for _, arg := range args {
+		used[arg] = true
+	}
+
+	var ids []string
+	for _, check := range checks.All() {
+		if !used[check.ID] {
+			ids = append(ids, fmt.Sprintf("%s\t%s", check.ID, check.Description))
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
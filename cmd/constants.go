@@ -4,6 +4,9 @@ const (
 	flagAllNamespaces = "--all-namespaces"
 	imageBusybox      = "busybox:latest"
 
+	// Annotation holding the revision of deployments and their replicasets
+	annotationRevision = "deployment.kubernetes.io/revision"
+
 	// Flag descriptions
 	FlagDescNamespace   = "Kubernetes namespace"
 	FlagDescSkipConfirm = "Skip confirmation prompt"
@@ -109,7 +109,7 @@ var contextListCmd = &cobra.Command{
 		currentName, _ := context.GetCurrentContextName()
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		fmt.Fprintln(w, "CURRENT\tNAME\tKUBECONFIG\tDEFAULT NS\tDESCRIPTION")
+		fmt.Fprintln(w, "CURRENT\tNAME\tKUBECONFIG\tDEFAULT NS\tPROTECTED\tDESCRIPTION")
 
 		for _, ctx := range contexts {
 			current := ""
@@ -124,7 +124,11 @@ var contextListCmd = &cobra.Command{
 			if defaultNS == "" {
 				defaultNS = "-"
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", current, ctx.Name, ctx.KubeconfigPath, defaultNS, description)
+			protected := "-"
+			if ctx.Protected {
+				protected = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", current, ctx.Name, ctx.KubeconfigPath, defaultNS, protected, description)
 		}
 
 		w.Flush()
@@ -172,11 +176,64 @@ var contextCurrentCmd = &cobra.Command{
 		if ctx.Description != "" {
 			fmt.Printf("Description: %s\n", ctx.Description)
 		}
+		if ctx.Protected {
+			fmt.Println("Protected: yes")
+		}
 
 		return nil
 	},
 }
 
+var contextProtectCmd = &cobra.Command{
+	Use:   "protect [name]",
+	Short: "Mark a context as protected",
+	Long: `Mark a context as protected (defaults to the current context).
+On a protected context, commands that change the cluster refuse to proceed
+unless confirmation is given explicitly with --yes.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setContextProtection(args, true)
+	},
+}
+
+var contextUnprotectCmd = &cobra.Command{
+	Use:   "unprotect [name]",
+	Short: "Remove protection from a context",
+	Long:  `Remove the protected mark from a context (defaults to the current context).`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setContextProtection(args, false)
+	},
+}
+
+// setContextProtection updates the protected mark of the named or current context
+func setContextProtection(args []string, protected bool) error {
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		currentName, err := context.GetCurrentContextName()
+		if err != nil {
+			return err
+		}
+		if currentName == "" {
+			return fmt.Errorf("no active context. Use 'kcsi context use <name>' first")
+		}
+		name = currentName
+	}
+
+	if err := context.SetProtected(name, protected); err != nil {
+		return err
+	}
+
+	if protected {
+		fmt.Printf("🔒 Context '%s' is now protected\n", name)
+	} else {
+		fmt.Printf("🔓 Context '%s' is no longer protected\n", name)
+	}
+	return nil
+}
+
 var contextRemoveCmd = &cobra.Command{
 	Use:     "remove <name>",
 	Aliases: []string{"rm", "delete"},
@@ -293,6 +350,8 @@ func init() {
 	contextCmd.AddCommand(contextSetNamespaceCmd)
 	contextCmd.AddCommand(contextClearNamespaceCmd)
 	contextCmd.AddCommand(contextGetNamespaceCmd)
+	contextCmd.AddCommand(contextProtectCmd)
+	contextCmd.AddCommand(contextUnprotectCmd)
 
 	// Add flags
 	contextAddCmd.Flags().StringP("description", "d", "", "Description of the context")
@@ -0,0 +1,657 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/completion"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"github.com/stanzinofree/kcsi/pkg/transfer"
+)
+
+var cpCmd = &cobra.Command{
+	Use:   "cp [source] [destination]",
+	Short: "Copy files and directories to and from containers",
+	Long: `Copy a file or directory between the local machine and a container.
+One side is a pod path written pod:path (or namespace/pod:path), the
+other a local path. Both pod names and container paths are completed.
+
+The copy streams a tar archive through kubectl exec. When the container
+has no tar, files are streamed base64 encoded instead, so images with
+only a shell and base64 work too. A progress line is shown on a terminal
+and every copied file is verified with sha256sum afterwards when the
+container has it.
+
+Examples:
+  kcsi cp -n production web-1:/etc/nginx/nginx.conf ./nginx.conf
+  kcsi cp -n production web-1:/var/log/app ./logs
+  kcsi cp -n production ./config.yaml web-1:/tmp/
+  kcsi cp -n production ./static web-1:/usr/share/nginx/html -c nginx`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: cpCompletion,
+	RunE:              runCp,
+}
+
+var (
+	cpNamespace string
+	cpContainer string
+	cpNoTar     bool
+	cpNoVerify  bool
+)
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+
+	cpCmd.Flags().StringVarP(&cpNamespace, "namespace", "n", "", FlagDescNamespace)
+	cpCmd.Flags().StringVarP(&cpContainer, "container", "c", "", "Container name (for multi-container pods)")
+	cpCmd.Flags().BoolVar(&cpNoTar, "no-tar", false, "Always stream files base64 encoded, even when the container has tar")
+	cpCmd.Flags().BoolVar(&cpNoVerify, "no-verify", false, "Skip the checksum verification after the copy")
+
+	cpCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
+	cpCmd.RegisterFlagCompletionFunc("container", cpContainerCompletion)
+}
+
+// cpScope returns the namespace of a pod location
+func cpScope(location transfer.Location) string {
+	if location.Namespace != "" {
+		return location.Namespace
+	}
+	return kubernetes.ResolveNamespace(cpNamespace)
+}
+
+// cpCompletion completes pod names as "pod:", container paths after the
+// colon, and local paths otherwise
+func cpCompletion(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) >= 2 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	location, err := transfer.ParseLocation(toComplete)
+	if err == nil && location.Remote() || strings.HasSuffix(toComplete, ":") && !strings.HasPrefix(toComplete, ".") && !strings.HasPrefix(toComplete, "/") {
+		return remotePathCompletion(toComplete)
+	}
+	if strings.HasPrefix(toComplete, ".") || strings.HasPrefix(toComplete, "/") || strings.Contains(toComplete, string(filepath.Separator)) {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	pods, err := kubernetes.GetPods(cpScope(transfer.Location{}))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	var completions []string
+	for _, pod := range pods {
+		if strings.HasPrefix(pod, toComplete) {
+			completions = append(completions, pod+":")
+		}
+	}
+	if len(completions) == 0 {
+		// No pod matches: complete local files
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// remotePathCompletion lists the entries of the directory being typed in
+// "pod:/some/dir/pre"
+func remotePathCompletion(toComplete string) ([]string, cobra.ShellCompDirective) {
+	podRef, typed, _ := strings.Cut(toComplete, ":")
+	location, err := transfer.ParseLocation(podRef + ":.")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	dir := "."
+	if i := strings.LastIndex(typed, "/"); i >= 0 {
+		dir = typed[:i+1]
+	}
+	remote := cpRemote{namespace: cpScope(location), pod: location.Pod, container: cpContainer}
+	output, err := remote.output(context.Background(), `cd "$1" 2>/dev/null && ls -1Ap`, dir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	prefix := podRef + ":"
+	if dir != "." {
+		prefix += dir
+	}
+	var completions []string
+	for _, entry := range strings.Split(strings.TrimSpace(output), "\n") {
+		if entry != "" {
+			completions = append(completions, prefix+entry)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+func cpContainerCompletion(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	for _, arg := range args {
+		location, err := transfer.ParseLocation(arg)
+		if err != nil || !location.Remote() {
+			continue
+		}
+		containers, err := kubernetes.GetContainers(cpScope(location), location.Pod)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return containers, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cpRemote runs commands in the container of a copy
+type cpRemote struct {
+	namespace string
+	pod       string
+	container string
+}
+
+// command builds a kubectl exec of the given command, with stdin attached
+// when requested
+func (r cpRemote) command(ctx context.Context, stdin bool, command ...string) *exec.Cmd {
+	args := []string{"exec"}
+	if stdin {
+		args = append(args, "-i")
+	}
+	args = append(args, "-n", r.namespace, r.pod)
+	if r.container != "" {
+		args = append(args, "-c", r.container)
+	}
+	args = append(args, "--")
+	return kubernetes.KubectlCommand(ctx, append(args, command...)...)
+}
+
+// script builds a kubectl exec running a sh script with positional arguments
+func (r cpRemote) script(ctx context.Context, stdin bool, script string, args ...string) *exec.Cmd {
+	return r.command(ctx, stdin, append([]string{"sh", "-c", script, "sh"}, args...)...)
+}
+
+// output runs a sh script and returns its output
+func (r cpRemote) output(ctx context.Context, script string, args ...string) (string, error) {
+	cmd := r.script(ctx, false, script, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return string(output), fmt.Errorf("%v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// probe inspects a container path and the tools available
+func (r cpRemote) probe(ctx context.Context, remotePath string) (transfer.Probe, error) {
+	output, err := r.output(ctx, transfer.ProbeScript, remotePath)
+	if err != nil {
+		return transfer.Probe{}, fmt.Errorf("failed to inspect %s:%s (kcsi cp needs sh in the container): %v", r.pod, remotePath, err)
+	}
+	return transfer.ParseProbe(output)
+}
+
+// run runs a command with stdin and stdout connected, reporting its stderr
+func (r cpRemote) run(cmd *exec.Cmd) error {
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func runCp(_ *cobra.Command, args []string) error {
+	src, err := transfer.ParseLocation(args[0])
+	if err != nil {
+		return err
+	}
+	dst, err := transfer.ParseLocation(args[1])
+	if err != nil {
+		return err
+	}
+	if src.Remote() == dst.Remote() {
+		return fmt.Errorf("exactly one of source and destination must be a pod path (pod:path)")
+	}
+
+	location := src
+	if dst.Remote() {
+		location = dst
+	}
+	remote := cpRemote{namespace: cpScope(location), pod: location.Pod}
+
+	pod, err := kubernetes.GetPod(remote.namespace, remote.pod)
+	if err != nil {
+		return fmt.Errorf("failed to get pod %s: %v", remote.pod, err)
+	}
+	container, ok := pod.Container(cpContainer)
+	if !ok {
+		return fmt.Errorf("container '%s' not found in pod %s", cpContainer, remote.pod)
+	}
+	remote.container = container.Name
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if src.Remote() {
+		return copyFromPod(ctx, remote, src.Path, dst.Path)
+	}
+	return copyToPod(ctx, remote, src.Path, dst.Path)
+}
+
+// copyFromPod downloads a container file or directory
+func copyFromPod(ctx context.Context, remote cpRemote, remotePath, localPath string) error {
+	probe, err := remote.probe(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+	switch probe.Type {
+	case transfer.TypeMissing:
+		return fmt.Errorf("%s:%s does not exist", remote.pod, remotePath)
+	case transfer.TypeOther:
+		return fmt.Errorf("%s:%s is not a regular file or directory", remote.pod, remotePath)
+	}
+
+	remoteDir, remoteBase := transfer.Split(remotePath)
+	destDir, name := filepath.Dir(localPath), filepath.Base(localPath)
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		destDir, name = localPath, remoteBase
+	} else if _, err := os.Stat(destDir); err != nil {
+		return fmt.Errorf("local directory %s does not exist", destDir)
+	}
+
+	// Files still being written (logs) change between reading and verifying,
+	// so their checksums are also taken before the copy
+	var before transfer.Sums
+	if canVerify(probe) {
+		before, _ = remoteSums(ctx, remote, remoteDir, remoteBase)
+	}
+
+	label := fmt.Sprintf("%s:%s → %s", remote.pod, remotePath, filepath.Join(destDir, name))
+	var counter transfer.Counter
+	stopProgress := showCopyProgress(label, &counter, probe.Size)
+
+	var sums transfer.Sums
+	method := "tar"
+	if probe.Has("tar") && !cpNoTar {
+		sums, err = downloadTar(ctx, remote, remoteDir, remoteBase, destDir, name, &counter)
+	} else {
+		method = "base64"
+		sums, err = downloadBase64(ctx, remote, probe, remoteDir, remoteBase, destDir, name, &counter)
+	}
+	stopProgress()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Copied %d file(s), %s via %s: %s\n", len(sums), formatBytes(counter.Bytes()), method, label)
+	return verifyCopy(ctx, remote, probe, remoteDir, remoteBase, sums, before)
+}
+
+// downloadTar streams a tar archive of the container path and extracts it
+func downloadTar(ctx context.Context, remote cpRemote, remoteDir, remoteBase, destDir, name string, counter *transfer.Counter) (transfer.Sums, error) {
+	cmd := remote.command(ctx, false, "tar", "cf", "-", "-C", remoteDir, remoteBase)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	sums, skipped, extractErr := transfer.ExtractTar(stdout, destDir, remoteBase, name, counter)
+	if extractErr != nil {
+		// Stop kubectl so it does not block writing the rest of the archive
+		cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+	if extractErr != nil {
+		return nil, extractErr
+	}
+	if waitErr != nil {
+		return nil, fmt.Errorf("tar failed in the container: %v %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	reportSkipped(skipped)
+	return sums, nil
+}
+
+// downloadBase64 copies a container file, or every file of a directory,
+// one base64 stream per file for containers without tar
+func downloadBase64(ctx context.Context, remote cpRemote, probe transfer.Probe, remoteDir, remoteBase, destDir, name string, counter *transfer.Counter) (transfer.Sums, error) {
+	if !probe.Has("base64") {
+		return nil, fmt.Errorf("the container has neither tar nor base64, files cannot be copied")
+	}
+
+	files := []string{remoteBase}
+	if probe.Type == transfer.TypeDir {
+		if !probe.Has("find") {
+			return nil, fmt.Errorf("copying a directory without tar needs find in the container")
+		}
+		dirs, err := remote.output(ctx, `cd "$1" && find "$2" -type d`, remoteDir, remoteBase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", remoteBase, err)
+		}
+		for _, dir := range splitLines(dirs) {
+			target, err := transfer.LocalTarget(destDir, dir, remoteBase, name)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return nil, err
+			}
+		}
+		listed, err := remote.output(ctx, `cd "$1" && find "$2" -type f`, remoteDir, remoteBase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", remoteBase, err)
+		}
+		files = splitLines(listed)
+	}
+
+	sums := transfer.Sums{}
+	for _, file := range files {
+		target, err := transfer.LocalTarget(destDir, file, remoteBase, name)
+		if err != nil {
+			return nil, err
+		}
+
+		cmd := remote.script(ctx, false, `cd "$1" && base64 < "$2"`, remoteDir, file)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		sum, writeErr := transfer.WriteFile(target, base64.NewDecoder(base64.StdEncoding, stdout), 0644, counter)
+		if writeErr != nil {
+			cmd.Process.Kill()
+		}
+		waitErr := cmd.Wait()
+		if writeErr != nil {
+			return nil, writeErr
+		}
+		if waitErr != nil {
+			return nil, fmt.Errorf("failed to read %s: %v %s", file, waitErr, strings.TrimSpace(stderr.String()))
+		}
+		sums[file] = sum
+	}
+	return sums, nil
+}
+
+// copyToPod uploads a local file or directory into the container
+func copyToPod(ctx context.Context, remote cpRemote, localPath, remotePath string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file or directory", localPath)
+	}
+
+	probe, err := remote.probe(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+	remoteDir, name := transfer.Split(remotePath)
+	switch {
+	case probe.Type == transfer.TypeDir:
+		remoteDir, name = path.Clean(remotePath), filepath.Base(localPath)
+	case probe.Type == transfer.TypeFile && info.IsDir():
+		return fmt.Errorf("cannot copy directory %s onto file %s:%s", localPath, remote.pod, remotePath)
+	case probe.Type == transfer.TypeOther:
+		return fmt.Errorf("%s:%s is not a regular file or directory", remote.pod, remotePath)
+	case !probe.ParentDir:
+		return fmt.Errorf("directory %s does not exist in %s", remoteDir, remote.pod)
+	}
+
+	dirs, files, err := transfer.LocalFiles(localPath, name)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			total += fi.Size()
+		}
+	}
+
+	label := fmt.Sprintf("%s → %s:%s", localPath, remote.pod, path.Join(remoteDir, name))
+	var counter transfer.Counter
+	stopProgress := showCopyProgress(label, &counter, total)
+
+	var sums transfer.Sums
+	method := "tar"
+	if probe.Has("tar") && !cpNoTar {
+		sums, err = uploadTar(ctx, remote, localPath, remoteDir, name, &counter)
+	} else {
+		method = "base64"
+		sums, err = uploadBase64(ctx, remote, probe, remoteDir, dirs, files, &counter)
+	}
+	stopProgress()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Copied %d file(s), %s via %s: %s\n", len(sums), formatBytes(counter.Bytes()), method, label)
+	return verifyCopy(ctx, remote, probe, remoteDir, name, sums, nil)
+}
+
+// uploadTar streams a tar archive of the local path into tar in the container
+func uploadTar(ctx context.Context, remote cpRemote, localPath, remoteDir, name string, counter *transfer.Counter) (transfer.Sums, error) {
+	reader, writer := io.Pipe()
+	cmd := remote.command(ctx, true, "tar", "xf", "-", "-C", remoteDir)
+	cmd.Stdin = reader
+
+	type result struct {
+		sums    transfer.Sums
+		skipped []string
+		err     error
+	}
+	written := make(chan result, 1)
+	go func() {
+		sums, skipped, err := transfer.WriteTar(writer, localPath, name, counter)
+		writer.CloseWithError(err)
+		written <- result{sums, skipped, err}
+	}()
+
+	runErr := remote.run(cmd)
+	// Unblock the archive writer if tar exited early
+	reader.Close()
+	archive := <-written
+	// A local read error closes the stream early, which also fails tar
+	if archive.err != nil {
+		return nil, archive.err
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("tar failed in the container: %v", runErr)
+	}
+	reportSkipped(archive.skipped)
+	return archive.sums, nil
+}
+
+// uploadBase64 creates the directories, then streams every file base64
+// encoded into base64 -d, for containers without tar
+func uploadBase64(ctx context.Context, remote cpRemote, probe transfer.Probe, remoteDir string, dirs []string, files map[string]string, counter *transfer.Counter) (transfer.Sums, error) {
+	if !probe.Has("base64") {
+		return nil, fmt.Errorf("the container has neither tar nor base64, files cannot be copied")
+	}
+
+	if len(dirs) > 0 {
+		args := append([]string{remoteDir}, dirs...)
+		if _, err := remote.output(ctx, `cd "$1" && shift && mkdir -p "$@"`, args...); err != nil {
+			return nil, fmt.Errorf("failed to create directories: %v", err)
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sums := transfer.Sums{}
+	for _, name := range names {
+		sum, err := uploadFileBase64(ctx, remote, remoteDir, name, files[name], counter)
+		if err != nil {
+			return nil, err
+		}
+		sums[name] = sum
+	}
+	return sums, nil
+}
+
+// uploadFileBase64 streams one file into the container and keeps its
+// permission bits
+func uploadFileBase64(ctx context.Context, remote cpRemote, remoteDir, name, localFile string, counter *transfer.Counter) (string, error) {
+	file, err := os.Open(localFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	reader, writer := io.Pipe()
+	hash := sha256.New()
+	go func() {
+		encoder := base64.NewEncoder(base64.StdEncoding, writer)
+		_, err := io.Copy(encoder, io.TeeReader(file, io.MultiWriter(hash, counter)))
+		if err == nil {
+			err = encoder.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	mode := fmt.Sprintf("%o", info.Mode().Perm())
+	cmd := remote.script(ctx, true, `cd "$1" && base64 -d > "$2" && chmod "$3" "$2"`, remoteDir, name, mode)
+	cmd.Stdin = reader
+	err = remote.run(cmd)
+	reader.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// canVerify reports whether the container can compute the checksums
+func canVerify(probe transfer.Probe) bool {
+	return !cpNoVerify && probe.Has("sha256sum") && probe.Has("find")
+}
+
+// remoteSums computes the checksums of the files under a container path
+func remoteSums(ctx context.Context, remote cpRemote, remoteDir, name string) (transfer.Sums, error) {
+	output, err := remote.output(ctx, transfer.SumScript, remoteDir, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute checksums in the container: %v", err)
+	}
+	return transfer.ParseSums(output), nil
+}
+
+// verifyCopy compares the checksums of the copied files with sha256sum in
+// the container. before holds the container checksums taken before a
+// download; files that changed since are reported with a warning.
+func verifyCopy(ctx context.Context, remote cpRemote, probe transfer.Probe, remoteDir, name string, sums, before transfer.Sums) error {
+	if cpNoVerify || len(sums) == 0 {
+		return nil
+	}
+	if !canVerify(probe) {
+		fmt.Println(colorize(colorYellow, "⚠️  Checksums not verified: sha256sum or find is missing in the container"))
+		return nil
+	}
+
+	after, err := remoteSums(ctx, remote, remoteDir, name)
+	if err != nil {
+		return err
+	}
+	mismatched, changed := transfer.Verify(sums, before, after)
+	if len(changed) > 0 {
+		fmt.Println(colorize(colorYellow, fmt.Sprintf("⚠️  %d file(s) changed in the container during or since the copy, not verified: %s", len(changed), strings.Join(changed, ", "))))
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("checksum mismatch for %d file(s): %s", len(mismatched), strings.Join(mismatched, ", "))
+	}
+	if verified := len(sums) - len(changed); verified > 0 {
+		fmt.Println(colorize(colorGreen, fmt.Sprintf("✓ Verified %d file(s) with sha256", verified)))
+	}
+	return nil
+}
+
+// showCopyProgress renders the bytes copied on a terminal until the
+// returned function is called
+func showCopyProgress(label string, counter *transfer.Counter, total int64) func() {
+	if !stdoutIsTerminal() {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		lastLine := ""
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			copied := counter.Bytes()
+			line := fmt.Sprintf("Copying %s  %s", label, formatBytes(copied))
+			if total > 0 {
+				percent := copied * 100 / total
+				if percent > 100 {
+					// Directory sizes from du are approximate
+					percent = 100
+				}
+				line += fmt.Sprintf(" / %s (%d%%)", formatBytes(total), percent)
+			}
+			lastLine = renderProgressLine(line, lastLine)
+
+			select {
+			case <-done:
+				fmt.Print("\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// reportSkipped lists the entries that were not copied
+func reportSkipped(skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Println(colorize(colorYellow, fmt.Sprintf("⚠️  Skipped %d symlink(s) or special file(s): %s", len(skipped), strings.Join(skipped, ", "))))
+}
+
+// formatBytes renders a byte count with a binary suffix ("1.5MiB")
+func formatBytes(n int64) string {
+	return kubernetes.FormatMemory(float64(n)) + "B"
+}
+
+// splitLines returns the non-empty lines of command output
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
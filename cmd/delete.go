@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/stanzinofree/kcsi/pkg/completion"
+	kcsicontext "github.com/stanzinofree/kcsi/pkg/context"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
 )
 
@@ -33,14 +34,19 @@ var (
 
 // askForConfirmation prompts the user for yes/no confirmation
 func askForConfirmation(resourceType, resourceName, namespace string) bool {
-	reader := bufio.NewReader(os.Stdin)
-
 	nsInfo := ""
 	if namespace != "" {
 		nsInfo = fmt.Sprintf(" in namespace '%s'", namespace)
 	}
 
-	fmt.Printf("Are you sure you want to delete %s '%s'%s? [y/N]: ", resourceType, resourceName, nsInfo)
+	return askYesNo(fmt.Sprintf("Are you sure you want to delete %s '%s'%s?", resourceType, resourceName, nsInfo))
+}
+
+// askYesNo prints a question and reads a yes/no answer from stdin (default no)
+func askYesNo(question string) bool {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Printf("%s [y/N]: ", question)
 
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -51,6 +57,30 @@ func askForConfirmation(resourceType, resourceName, namespace string) bool {
 	return response == "y" || response == "yes"
 }
 
+// confirmClusterChange asks the user to confirm a change to the cluster.
+// When the current kcsi context is protected, the interactive prompt is not
+// accepted and the change only proceeds with --yes.
+func confirmClusterChange(question string, yes bool) (bool, error) {
+	if yes {
+		return true, nil
+	}
+
+	if err := guardProtectedContext(yes); err != nil {
+		return false, err
+	}
+
+	return askYesNo(question), nil
+}
+
+// guardProtectedContext refuses a change to a protected kcsi context unless
+// --yes was given, for commands that do not otherwise ask for confirmation
+func guardProtectedContext(yes bool) error {
+	if !yes && kcsicontext.IsCurrentContextProtected() {
+		return fmt.Errorf("current context is protected, re-run with --yes to confirm the change")
+	}
+	return nil
+}
+
 // Generic kubectl delete command runner with confirmation
 func runKubectlDelete(resourceType, namespace string, args []string, force bool) error {
 	if len(args) == 0 {
@@ -1,21 +1,30 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/diff"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
 )
 
 var editCmd = &cobra.Command{
 	Use:   "edit [resource-type] [name]",
 	Short: "Edit a resource with automatic backup",
-	Long:  "Edit a resource on the server using the default editor. A backup of the current state is automatically saved before editing.",
-	Args:  cobra.MinimumNArgs(2),
-	RunE:  runEdit,
+	Long: `Edit a resource on the server using the default editor. A backup of the current state is automatically saved before editing.
+
+After the editor closes, the updated resource is compared with the backup and
+the changed fields are shown (metadata noise such as resourceVersion,
+generation and managedFields is ignored).
+
+With --revert, kcsi waits for the rollout of deployments, daemonsets and
+statefulsets and re-applies the backup if the rollout fails.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runEdit,
 }
 
 func init() {
@@ -26,6 +35,8 @@ func init() {
 	editCmd.Flags().String("backup-dir", "", "Directory to save backups (defaults to ~/.kcsi/backups)")
 	editCmd.Flags().Bool("no-backup", false, "Skip automatic backup before editing")
 	editCmd.Flags().StringP("editor", "e", "", "Editor to use (defaults to KUBE_EDITOR or EDITOR environment variable)")
+	editCmd.Flags().Bool("revert", false, "Re-apply the backup if the rollout after the edit fails")
+	editCmd.Flags().Duration("timeout", 5*time.Minute, "How long to wait for the rollout when --revert is set")
 
 	editCmd.RegisterFlagCompletionFunc("namespace", func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 		namespaces, err := kubernetes.GetNamespaces()
@@ -44,10 +55,15 @@ func runEdit(cmd *cobra.Command, args []string) error {
 	backupDir, _ := cmd.Flags().GetString("backup-dir")
 	noBackup, _ := cmd.Flags().GetBool("no-backup")
 	editor, _ := cmd.Flags().GetString("editor")
+	revert, _ := cmd.Flags().GetBool("revert")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
 
 	if namespace == "" {
 		return fmt.Errorf("namespace is required (use -n flag)")
 	}
+	if revert && noBackup {
+		return fmt.Errorf("--revert requires a backup and cannot be combined with --no-backup")
+	}
 
 	// Create backup unless --no-backup is specified
 	var backupPath string
@@ -87,14 +103,141 @@ func runEdit(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println()
+	if noBackup || backupPath == "" {
+		fmt.Println("✅ Resource updated successfully")
+		return nil
+	}
+
+	// The diff is informational: when it fails, the rollout is still
+	// watched so --revert can restore the backup
+	changes, err := diffAgainstBackup(resourceType, resourceName, namespace, backupPath)
+	if err == nil && len(changes) == 0 {
+		fmt.Println("ℹ️  No changes detected")
+		return nil
+	}
+
 	fmt.Println("✅ Resource updated successfully")
-	if !noBackup && backupPath != "" {
-		fmt.Printf("💾 Previous state backed up at: %s\n", backupPath)
+	if err != nil {
+		fmt.Printf("⚠️  Could not compare with backup: %v\n", err)
+	} else {
+		fmt.Println()
+		printFieldChanges(changes)
+		fmt.Println()
+	}
+	fmt.Printf("💾 Previous state backed up at: %s\n", backupPath)
+
+	if revert {
+		return waitForRolloutOrRevert(resourceType, resourceName, namespace, backupPath, timeout)
 	}
 
 	return nil
 }
 
+// diffAgainstBackup fetches the live resource and compares it with the backup,
+// ignoring status and server-managed metadata
+func diffAgainstBackup(resourceType, resourceName, namespace, backupPath string) ([]diff.Change, error) {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %v", err)
+	}
+
+	before, err := diff.LoadObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := kubernetes.GetObject(resourceType, resourceName, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource state: %v", err)
+	}
+	if after == nil {
+		return nil, fmt.Errorf("resource %s/%s no longer exists", resourceType, resourceName)
+	}
+
+	return diff.Objects(diff.StripNoise(before), diff.StripNoise(after)), nil
+}
+
+// printFieldChanges renders a field-level diff with old and new values
+func printFieldChanges(changes []diff.Change) {
+	fmt.Printf("Changed fields (%d):\n", len(changes))
+	for _, change := range changes {
+		switch change.Type {
+		case diff.Added:
+			fmt.Printf("  %s %s: %s\n", colorize(colorGreen, "+"), change.Path, diff.FormatValue(change.New))
+		case diff.Removed:
+			fmt.Printf("  %s %s: %s\n", colorize(colorRed, "-"), change.Path, diff.FormatValue(change.Old))
+		default:
+			fmt.Printf("  %s %s: %s → %s\n", colorize(colorYellow, "~"), change.Path,
+				diff.FormatValue(change.Old), diff.FormatValue(change.New))
+		}
+	}
+}
+
+// waitForRolloutOrRevert waits for the rollout triggered by the edit and
+// restores the backup if it does not complete
+func waitForRolloutOrRevert(resourceType, resourceName, namespace, backupPath string, timeout time.Duration) error {
+	if rolloutKind(resourceType) == "" {
+		fmt.Printf("ℹ️  %s has no rollout to watch, --revert ignored\n", resourceType)
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("⏳ Waiting for rollout of %s/%s (timeout %s)...\n", resourceType, resourceName, timeout)
+
+	_, err := kubernetes.ExecuteKubectl("rollout", "status", resourceType, resourceName,
+		"-n", namespace, fmt.Sprintf("--timeout=%s", timeout))
+	if err == nil {
+		fmt.Println("✅ Rollout completed")
+		return nil
+	}
+
+	fmt.Printf("❌ Rollout failed: %v\n", err)
+	fmt.Printf("↩️  Reverting to backup %s...\n", backupPath)
+
+	if revertErr := restoreBackup(backupPath); revertErr != nil {
+		return fmt.Errorf("rollout failed and revert failed: %v", revertErr)
+	}
+
+	fmt.Println("✅ Backup re-applied")
+	return fmt.Errorf("rollout of %s/%s failed, changes were reverted", resourceType, resourceName)
+}
+
+// restoreBackup replaces the live resource with the backed up state
+func restoreBackup(backupPath string) error {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %v", err)
+	}
+
+	obj, err := diff.LoadObject(data)
+	if err != nil {
+		return err
+	}
+
+	// Without resourceVersion the replace is not rejected as a conflict
+	restored, err := json.Marshal(diff.StripNoise(obj))
+	if err != nil {
+		return fmt.Errorf("failed to prepare backup: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "kcsi-revert-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(restored); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temporary file: %v", err)
+	}
+	tmpFile.Close()
+
+	if _, err := kubernetes.ExecuteKubectl("replace", "-f", tmpFile.Name()); err != nil {
+		return err
+	}
+	return nil
+}
+
 func createResourceBackup(resourceType, resourceName, namespace, outputFormat, backupDir string) (string, error) {
 	// Determine backup directory
 	if backupDir == "" {
@@ -9,27 +9,50 @@ import (
 )
 
 var executeCmd = &cobra.Command{
-	Use:   "execute [pod-name] -- [command...]",
-	Short: "Execute a command in a pod",
+	Use:     "execute [pod-name|type/name] -- [command...]",
+	Aliases: []string{"exec"},
+	Short:   "Execute a command in a pod, or in many pods at once",
 	Long: `Execute a specific command in a pod.
 Use -n to specify namespace first for better autocompletion.
 Use -- to separate the pod name from the command to execute.
 
+With --selector (-l), or --all and a type/name (deploy/web, sts/db,
+ds/agent, svc/web), the command runs concurrently in every matching
+running pod, at most --parallel at a time. The output is grouped per pod
+with its exit code, followed by a summary of the pods whose output or
+exit code differs from the others.
+
 Examples:
   kcsi execute -n production my-pod -- ls -la
   kcsi execute -n production my-pod -c sidecar -- cat /etc/hosts
-  kcsi execute -n default api-pod -- curl localhost:8080/health`,
+  kcsi execute -n default api-pod -- curl localhost:8080/health
+  kcsi exec -n production -l app=web -- cat /etc/config.yaml
+  kcsi exec -n production deploy/web --all -- sha256sum /app/bin/server`,
 	Args:              cobra.MinimumNArgs(1),
 	RunE:              runExecute,
-	ValidArgsFunction: completion.PodCompletion,
+	ValidArgsFunction: executeCompletion,
 }
 
 var (
 	executeNamespace string
 	executeContainer string
+	executeSelector  string
+	executeAll       bool
+	executeParallel  int
 )
 
-func runExecute(_ *cobra.Command, args []string) error {
+// executeCompletion completes the pod or type/name before the command
+func executeCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || executeSelector != "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return workloadRefCompletion(cmd, args, toComplete, kubernetes.InjectDefaultNamespace(executeNamespace))
+}
+
+func runExecute(cmd *cobra.Command, args []string) error {
+	if executeSelector != "" || executeAll {
+		return runExecuteMany(cmd, args)
+	}
 	if len(args) < 2 {
 		return fmt.Errorf("command is required after pod name (use -- to separate)")
 	}
@@ -65,6 +88,9 @@ func init() {
 
 	executeCmd.Flags().StringVarP(&executeNamespace, "namespace", "n", "", FlagDescNamespace)
 	executeCmd.Flags().StringVarP(&executeContainer, "container", "c", "", "Container name (for multi-container pods)")
+	executeCmd.Flags().StringVarP(&executeSelector, "selector", "l", "", "Run in every running pod matching this label selector")
+	executeCmd.Flags().BoolVar(&executeAll, "all", false, "Run in every running pod of the type/name instead of one")
+	executeCmd.Flags().IntVar(&executeParallel, "parallel", 10, "Maximum number of pods running the command at the same time")
 
 	executeCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
 	executeCmd.RegisterFlagCompletionFunc("container", completion.ContainerCompletion)
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"github.com/stanzinofree/kcsi/pkg/podexec"
+)
+
+// runExecuteMany runs the command after -- in every running pod matching
+// --selector, or every pod of a type/name with --all
+func runExecuteMany(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 || dash == len(args) {
+		return fmt.Errorf("command is required after -- (e.g. kcsi exec -l app=web -- cat /etc/hosts)")
+	}
+	refs, command := args[:dash], args[dash:]
+	if executeParallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	namespace := kubernetes.ResolveNamespace(executeNamespace)
+
+	pods, skipped, err := executeTargetPods(refs, namespace)
+	if err != nil {
+		return err
+	}
+	for _, s := range skipped {
+		fmt.Fprintln(os.Stderr, colorize(colorGray, "Skipping "+s))
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no running pods found in namespace %s", namespace)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	lastLine := renderProgressLine(fmt.Sprintf("Running in %d pods: 0/%d done", len(pods), len(pods)), "")
+	results := podexec.Run(ctx, pods, executeParallel, func(ctx context.Context, pod string) podexec.Result {
+		return execInPod(ctx, namespace, pod, command)
+	}, func(finished int) {
+		lastLine = renderProgressLine(fmt.Sprintf("Running in %d pods: %d/%d done", len(pods), finished, len(pods)), lastLine)
+	})
+	finishProgressLine()
+	fmt.Println()
+
+	failed := 0
+	for _, r := range results {
+		printExecResult(r)
+		if r.ExitCode != 0 {
+			failed++
+		}
+	}
+	printExecSummary(podexec.GroupResults(results), len(results))
+
+	if failed > 0 {
+		// Results are reported above, usage help would only add noise
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return &exitCodeError{
+			code:    1,
+			message: fmt.Sprintf("command failed in %d of %d pods", failed, len(results)),
+		}
+	}
+	return nil
+}
+
+// executeTargetPods lists the running pods the command runs in, and
+// describes the matching pods that are skipped
+func executeTargetPods(refs []string, namespace string) ([]string, []string, error) {
+	var selector, podName string
+	switch {
+	case executeSelector != "" && len(refs) > 0:
+		return nil, nil, fmt.Errorf("use either --selector or a pod/type/name with --all, not both")
+	case executeSelector != "":
+		selector = executeSelector
+	case len(refs) != 1:
+		return nil, nil, fmt.Errorf("--all requires exactly one type/name before -- (e.g. deploy/web)")
+	case !strings.Contains(refs[0], "/"):
+		podName = refs[0]
+	default:
+		var err error
+		if selector, podName, err = resolveLogSelector(refs[0], namespace); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if podName != "" {
+		return []string{podName}, nil, nil
+	}
+
+	pods, err := kubernetes.ListPods(namespace, selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	var names, skipped []string
+	for _, pod := range pods {
+		switch {
+		case pod.Metadata.DeletionTimestamp != nil:
+			skipped = append(skipped, pod.Metadata.Name+" (terminating)")
+		case pod.Status.Phase != "Running":
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", pod.Metadata.Name, pod.Status.Phase))
+		default:
+			names = append(names, pod.Metadata.Name)
+		}
+	}
+	return names, skipped, nil
+}
+
+// execInPod runs the command in one pod and captures its combined output
+// and exit code
+func execInPod(ctx context.Context, namespace, pod string, command []string) podexec.Result {
+	args := []string{"exec", "-n", namespace, pod}
+	if executeContainer != "" {
+		args = append(args, "-c", executeContainer)
+	}
+	args = append(args, "--")
+	args = append(args, command...)
+
+	output, err := kubernetes.KubectlCommand(ctx, args...).CombinedOutput()
+	result := podexec.Result{Pod: pod, Output: stripExitCodeNotice(string(output))}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && ctx.Err() == nil:
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.ExitCode = -1
+		result.Err = err
+	}
+	return result
+}
+
+// stripExitCodeNotice drops the "command terminated with exit code N" line
+// kubectl adds, since the exit code is shown separately
+func stripExitCodeNotice(output string) string {
+	trimmed := strings.TrimRight(output, "\n")
+	if i := strings.LastIndex(trimmed, "\n"); strings.HasPrefix(trimmed[i+1:], "command terminated with exit code ") {
+		return trimmed[:i+1]
+	}
+	return output
+}
+
+// printExecResult prints the output of one pod under a header with its
+// exit code
+func printExecResult(r podexec.Result) {
+	status := colorize(colorGreen, "exit 0")
+	switch {
+	case r.Err != nil:
+		status = colorize(colorRed, "failed: "+r.Err.Error())
+	case r.ExitCode != 0:
+		status = colorize(colorRed, fmt.Sprintf("exit %d", r.ExitCode))
+	}
+	fmt.Printf("%s %s (%s)\n", colorize(colorCyan, "==>"), colorize(colorBold, r.Pod), status)
+	if r.Output != "" {
+		fmt.Print(r.Output)
+		if !strings.HasSuffix(r.Output, "\n") {
+			fmt.Println()
+		}
+	}
+	fmt.Println()
+}
+
+// printExecSummary shows whether every pod returned the same result, and
+// otherwise which pods differ from the majority
+func printExecSummary(groups []podexec.Group, total int) {
+	if len(groups) == 1 {
+		message := fmt.Sprintf("✓ All %d pods returned the same output (exit %d)", total, groups[0].ExitCode)
+		color := colorGreen
+		if groups[0].ExitCode != 0 {
+			color = colorYellow
+		}
+		fmt.Println(colorize(color, message))
+		return
+	}
+
+	fmt.Println(colorize(colorBold, fmt.Sprintf("%d different results across %d pods:", len(groups), total)))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	for i, g := range groups {
+		note := "differs"
+		if i == 0 && len(g.Pods) > len(groups[1].Pods) {
+			note = "majority"
+		}
+		fmt.Fprintf(w, "  %d pod(s)\texit %d\t%s\t%s\n", len(g.Pods), g.ExitCode, note, strings.Join(g.Pods, ", "))
+	}
+	w.Flush()
+}
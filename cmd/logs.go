@@ -1,28 +1,69 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stanzinofree/kcsi/pkg/completion"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"github.com/stanzinofree/kcsi/pkg/logfilter"
 )
 
 var logsCmd = &cobra.Command{
-	Use:               "logs [pod-name]",
-	Short:             "Get logs from a pod",
-	Long:              `Get logs from a specific pod with namespace and pod name autocompletion`,
-	Args:              cobra.ExactArgs(1),
+	Use:   "logs [pod-name | type/name]",
+	Short: "Get logs from a pod",
+	Long: `Get logs from a specific pod with namespace and pod name autocompletion.
+
+Logs of every pod behind a deployment, statefulset, daemonset, replicaset, job
+or service can be streamed at once with type/name, or with a label selector.
+Each line is prefixed with a colored pod/container tag. When following, new
+pods are picked up as they appear (e.g. during a rollout) and streams of
+terminated pods are closed.
+
+JSON log lines can be filtered and reduced to selected fields. Non-JSON lines
+pass through untouched (only --grep applies to them).
+
+With --export, the current and previous logs of every matching pod and
+container are written to files and bundled in a tar.gz together with a
+manifest of the pods, their restart counts and the time range.
+
+Examples:
+  kcsi logs -n production my-pod
+  kcsi logs -n production deploy/web -f
+  kcsi logs -n production -l app=web --all-containers -f
+  kcsi logs -n production deploy/web --fields ts,level,msg --level warn+
+  kcsi logs -n production my-pod --where user_id=42 --grep 'timeout|refused'
+  kcsi logs -n production deploy/web --since 1h --timestamps
+  kcsi logs -n production deploy/web --since-time 2026-01-09T10:00:00Z --until 2026-01-09T11:00:00Z
+  kcsi logs -n production -l app=web --since 2h --export ./incident-1234`,
+	Args:              cobra.MaximumNArgs(1),
 	RunE:              runLogs,
-	ValidArgsFunction: completion.PodCompletion,
+	ValidArgsFunction: logsCompletion,
 }
 
 var (
-	logsNamespace string
-	logsFollow    bool
-	logsPrevious  bool
-	logsTail      int64
-	logsContainer string
+	logsNamespace     string
+	logsFollow        bool
+	logsPrevious      bool
+	logsTail          int64
+	logsContainer     string
+	logsSelector      string
+	logsAllContainers bool
+	logsFields        []string
+	logsLevel         string
+	logsWhere         []string
+	logsGrep          string
+	logsSince         string
+	logsSinceTime     string
+	logsUntil         string
+	logsTimestamps    bool
+	logsExport        string
 )
 
 func init() {
@@ -34,14 +75,175 @@ func init() {
 	logsCmd.Flags().BoolVarP(&logsPrevious, "previous", "p", false, "Print the logs for the previous instance of the container")
 	logsCmd.Flags().Int64Var(&logsTail, "tail", -1, "Lines of recent log file to display (default: all)")
 	logsCmd.Flags().StringVarP(&logsContainer, "container", "c", "", "Container name (for multi-container pods)")
+	logsCmd.Flags().StringVarP(&logsSelector, "selector", "l", "", "Stream logs from all pods matching a label selector (e.g. app=web)")
+	logsCmd.Flags().BoolVar(&logsAllContainers, "all-containers", false, "Stream logs from all containers of each pod")
+	logsCmd.Flags().StringSliceVar(&logsFields, "fields", nil, "Print only these fields of JSON log lines (e.g. ts,level,msg)")
+	logsCmd.Flags().StringVar(&logsLevel, "level", "", "Show only JSON lines with this level, or this level and above with + (e.g. warn+)")
+	logsCmd.Flags().StringArrayVar(&logsWhere, "where", nil, "Show only JSON lines where a field matches (key=value or key!=value, repeatable)")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Show only lines matching a regular expression, with matches highlighted")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only return logs newer than a relative duration (e.g. 10m, 2h)")
+	logsCmd.Flags().StringVar(&logsSinceTime, "since-time", "", "Only return logs after a specific time (RFC3339)")
+	logsCmd.Flags().StringVar(&logsUntil, "until", "", "Only return logs before a specific time (RFC3339) or relative duration ago (e.g. 30m)")
+	logsCmd.Flags().BoolVar(&logsTimestamps, "timestamps", false, "Include timestamps on each line")
+	logsCmd.Flags().StringVar(&logsExport, "export", "", "Export current and previous logs of all matching pods to a tar.gz in this directory")
 
 	logsCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
 	logsCmd.RegisterFlagCompletionFunc("container", completion.ContainerCompletion)
 }
 
+// logsCompletion completes pod names, or workload names after a type/ prefix
+func logsCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return workloadRefCompletion(cmd, args, toComplete, kubernetes.InjectDefaultNamespace(logsNamespace))
+}
+
+// workloadRefCompletion completes pod names, or deployment, statefulset,
+// daemonset and service names after a type/ prefix
+func workloadRefCompletion(cmd *cobra.Command, args []string, toComplete, namespace string) ([]string, cobra.ShellCompDirective) {
+	resourceType, _, found := strings.Cut(toComplete, "/")
+	if !found {
+		return completion.PodCompletion(cmd, args, toComplete)
+	}
+
+	var names []string
+	var err error
+
+	switch resourceType {
+	case "deploy", "deployment":
+		names, err = kubernetes.GetDeployments(namespace)
+	case "sts", "statefulset":
+		names, err = kubernetes.GetStatefulSets(namespace)
+	case "ds", "daemonset":
+		names, err = kubernetes.GetDaemonSets(namespace)
+	case "svc", "service":
+		names, err = kubernetes.GetServices(namespace)
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := make([]string, 0, len(names))
+	for _, name := range names {
+		completions = append(completions, resourceType+"/"+name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
 func runLogs(_ *cobra.Command, args []string) error {
-	podName := args[0]
+	if len(args) == 0 && logsSelector == "" {
+		return fmt.Errorf("a pod name, type/name or --selector (-l) is required")
+	}
+	if len(args) > 0 && logsSelector != "" {
+		return fmt.Errorf("a pod name and --selector (-l) cannot be combined")
+	}
 
+	target := ""
+	if len(args) > 0 {
+		target = args[0]
+	}
+
+	window, err := parseLogTimeWindow()
+	if err != nil {
+		return err
+	}
+
+	if logsExport != "" {
+		if logsFollow {
+			return fmt.Errorf("--export cannot be combined with --follow")
+		}
+		namespace, selector, podName, err := resolveLogTargets(target)
+		if err != nil {
+			return err
+		}
+		return runLogsExport(logsExport, namespace, selector, podName, window)
+	}
+
+	pipeline, err := logfilter.NewPipeline(logsFields, logsLevel, logsWhere, logsGrep)
+	if err != nil {
+		return err
+	}
+	pipeline.Highlight = func(match string) string {
+		return colorize(colorBold+colorRed, match)
+	}
+	pipeline.Timestamped = window.timestamped()
+	pipeline.ShowTimestamps = logsTimestamps
+	pipeline.Until = window.until
+
+	// A single pod without --all-containers is read directly from kubectl
+	if logsSelector == "" && !logsAllContainers && !strings.Contains(target, "/") {
+		return runSinglePodLogs(target, pipeline, window)
+	}
+
+	return runAggregatedLogs(target, pipeline, window)
+}
+
+// logTimeWindow holds the validated time range options of the logs command
+type logTimeWindow struct {
+	since      string
+	sinceTime  string
+	until      time.Time
+	timestamps bool
+}
+
+// timestamped reports whether kubectl must prefix lines with timestamps,
+// which --until needs to filter lines client-side
+func (w logTimeWindow) timestamped() bool {
+	return w.timestamps || !w.until.IsZero()
+}
+
+// kubectlArgs returns the kubectl logs flags for the time window
+func (w logTimeWindow) kubectlArgs() []string {
+	var args []string
+	if w.since != "" {
+		args = append(args, "--since="+w.since)
+	}
+	if w.sinceTime != "" {
+		args = append(args, "--since-time="+w.sinceTime)
+	}
+	if w.timestamped() {
+		args = append(args, "--timestamps")
+	}
+	return args
+}
+
+func parseLogTimeWindow() (logTimeWindow, error) {
+	window := logTimeWindow{since: logsSince, sinceTime: logsSinceTime, timestamps: logsTimestamps}
+
+	if logsSince != "" && logsSinceTime != "" {
+		return window, fmt.Errorf("--since and --since-time cannot be combined")
+	}
+	if logsSince != "" {
+		if _, err := time.ParseDuration(logsSince); err != nil {
+			return window, fmt.Errorf("invalid --since duration '%s' (e.g. 10m, 2h)", logsSince)
+		}
+	}
+	if logsSinceTime != "" {
+		if _, err := time.Parse(time.RFC3339, logsSinceTime); err != nil {
+			return window, fmt.Errorf("invalid --since-time '%s', expected RFC3339 (e.g. 2026-01-09T10:00:00Z)", logsSinceTime)
+		}
+	}
+
+	if logsUntil != "" {
+		if logsFollow {
+			return window, fmt.Errorf("--until cannot be combined with --follow")
+		}
+		if ago, err := time.ParseDuration(logsUntil); err == nil {
+			window.until = time.Now().Add(-ago)
+		} else if until, err := time.Parse(time.RFC3339, logsUntil); err == nil {
+			window.until = until
+		} else {
+			return window, fmt.Errorf("invalid --until '%s', expected RFC3339 time or duration (e.g. 30m)", logsUntil)
+		}
+	}
+
+	return window, nil
+}
+
+func runSinglePodLogs(podName string, pipeline *logfilter.Pipeline, window logTimeWindow) error {
 	// Build kubectl command with namespace injection
 	kubectlArgs := kubernetes.BuildNamespaceArgs([]string{"logs"}, logsNamespace)
 	kubectlArgs = append(kubectlArgs, podName)
@@ -62,6 +264,69 @@ func runLogs(_ *cobra.Command, args []string) error {
 		kubectlArgs = append(kubectlArgs, "-c", logsContainer)
 	}
 
-	// Execute kubectl using the helper
-	return kubernetes.ExecuteKubectlInteractive(kubectlArgs...)
+	kubectlArgs = append(kubectlArgs, window.kubectlArgs()...)
+
+	// Without processing, output is passed straight through
+	if !pipeline.Active() {
+		return kubernetes.ExecuteKubectlInteractive(kubectlArgs...)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := streamKubectlLines(ctx, podName, kubectlArgs, func(line string) {
+		if out, ok := pipeline.Process(line); ok {
+			fmt.Println(out)
+		}
+	})
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("kubectl error: %v", err)
+	}
+	return nil
+}
+
+// resolveLogTargets returns the namespace and either the label selector or
+// the pod name the logs command reads from
+func resolveLogTargets(target string) (namespace, selector, podName string, err error) {
+	namespace = kubernetes.ResolveNamespace(logsNamespace)
+
+	selector = logsSelector
+	if target == "" {
+		return namespace, selector, "", nil
+	}
+
+	if !strings.Contains(target, "/") {
+		return namespace, selector, target, nil
+	}
+
+	selector, podName, err = resolveLogSelector(target, namespace)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to resolve %s: %v", target, err)
+	}
+	return namespace, selector, podName, nil
+}
+
+// runAggregatedLogs streams logs of several pods and containers concurrently
+func runAggregatedLogs(target string, pipeline *logfilter.Pipeline, window logTimeWindow) error {
+	if logsPrevious {
+		return fmt.Errorf("--previous is only supported for a single pod and container")
+	}
+
+	namespace, selector, podName, err := resolveLogTargets(target)
+	if err != nil {
+		return err
+	}
+
+	aggregator := newLogAggregator(namespace, selector, podName)
+	aggregator.container = logsContainer
+	aggregator.allContainers = logsAllContainers
+	aggregator.follow = logsFollow
+	aggregator.tail = logsTail
+	aggregator.pipeline = pipeline
+	aggregator.windowArgs = window.kubectlArgs()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return aggregator.run(ctx)
 }
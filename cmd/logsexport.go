@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	kcsicontext "github.com/stanzinofree/kcsi/pkg/context"
+	"github.com/stanzinofree/kcsi/pkg/logfilter"
+	"gopkg.in/yaml.v3"
+)
+
+const logsManifestFile = "manifest.yaml"
+
+// logsManifest describes the content of a logs export bundle
+type logsManifest struct {
+	GeneratedAt time.Time         `yaml:"generated_at"`
+	Context     string            `yaml:"context,omitempty"`
+	Namespace   string            `yaml:"namespace"`
+	Selector    string            `yaml:"selector,omitempty"`
+	TimeRange   logsManifestRange `yaml:"time_range"`
+	Pods        []logsManifestPod `yaml:"pods"`
+	Errors      []string          `yaml:"errors,omitempty"`
+}
+
+type logsManifestRange struct {
+	Since     string `yaml:"since,omitempty"`
+	SinceTime string `yaml:"since_time,omitempty"`
+	Until     string `yaml:"until,omitempty"`
+}
+
+type logsManifestPod struct {
+	Name       string                  `yaml:"name"`
+	Node       string                  `yaml:"node,omitempty"`
+	Phase      string                  `yaml:"phase"`
+	Containers []logsManifestContainer `yaml:"containers"`
+}
+
+type logsManifestContainer struct {
+	Name         string   `yaml:"name"`
+	RestartCount int      `yaml:"restart_count"`
+	Files        []string `yaml:"files"`
+}
+
+// runLogsExport writes the current and previous logs of every matching pod
+// and container to a directory and bundles it into a tar.gz
+func runLogsExport(dir, namespace, selector, podName string, window logTimeWindow) error {
+	aggregator := newLogAggregator(namespace, selector, podName)
+	aggregator.container = logsContainer
+	aggregator.allContainers = logsContainer == ""
+
+	pods, err := aggregator.listPods()
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %v", err)
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no pods found to export logs from")
+	}
+
+	now := time.Now()
+	bundleName := fmt.Sprintf("kcsi-logs-%s-%s", namespace, now.Format("20060102-150405"))
+	bundleDir := filepath.Join(dir, bundleName)
+	if err := os.MkdirAll(bundleDir, 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	manifest := logsManifest{
+		GeneratedAt: now.UTC(),
+		Namespace:   namespace,
+		Selector:    selector,
+		TimeRange: logsManifestRange{
+			Since:     window.since,
+			SinceTime: window.sinceTime,
+		},
+	}
+	if !window.until.IsZero() {
+		manifest.TimeRange.Until = window.until.UTC().Format(time.RFC3339)
+	}
+	if name, err := kcsicontext.GetCurrentContextName(); err == nil {
+		manifest.Context = name
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("📦 Exporting logs of %d pod(s) from namespace %s\n", len(pods), namespace)
+
+	for _, pod := range pods {
+		entry := logsManifestPod{
+			Name:  pod.Metadata.Name,
+			Node:  pod.Spec.NodeName,
+			Phase: pod.Status.Phase,
+		}
+
+		restarts := map[string]int{}
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts[cs.Name] = cs.RestartCount
+		}
+
+		for _, container := range aggregator.selectContainers(pod) {
+			exported := logsManifestContainer{Name: container, RestartCount: restarts[container]}
+
+			// Previous logs only exist for containers that restarted
+			instances := []bool{false}
+			if restarts[container] > 0 {
+				instances = append(instances, true)
+			}
+
+			for _, previous := range instances {
+				fileName := container + ".log"
+				if previous {
+					fileName = container + ".previous.log"
+				}
+				relPath := filepath.Join(pod.Metadata.Name, fileName)
+				lines, err := exportContainerLogs(ctx, filepath.Join(bundleDir, relPath), namespace, pod.Metadata.Name, container, previous, window)
+				if err != nil {
+					if ctx.Err() != nil {
+						return fmt.Errorf("export interrupted")
+					}
+					manifest.Errors = append(manifest.Errors, fmt.Sprintf("%s: %v", relPath, err))
+					fmt.Printf("  ⚠️  %s: %v\n", relPath, err)
+					continue
+				}
+				exported.Files = append(exported.Files, filepath.ToSlash(relPath))
+				fmt.Printf("  ✓ %s (%d lines)\n", relPath, lines)
+			}
+
+			entry.Containers = append(entry.Containers, exported)
+		}
+
+		manifest.Pods = append(manifest.Pods, entry)
+	}
+
+	data, err := yaml.Marshal(&manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundleDir, logsManifestFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	archivePath := bundleDir + ".tar.gz"
+	if err := writeTarGz(archivePath, bundleDir, bundleName); err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	fmt.Printf("\n✅ Logs exported to %s\n", bundleDir)
+	fmt.Printf("📦 Archive: %s\n", archivePath)
+	if len(manifest.Errors) > 0 {
+		fmt.Printf("⚠️  %d file(s) could not be exported, see %s\n", len(manifest.Errors), logsManifestFile)
+	}
+	return nil
+}
+
+// exportContainerLogs writes the logs of one container to path, applying the
+// --until filter, and returns the number of lines written
+func exportContainerLogs(ctx context.Context, path, namespace, pod, container string, previous bool, window logTimeWindow) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	// Exported lines always carry timestamps so incidents can be correlated
+	pipeline, err := logfilter.NewPipeline(nil, "", nil, "")
+	if err != nil {
+		return 0, err
+	}
+	pipeline.Timestamped = true
+	pipeline.ShowTimestamps = true
+	pipeline.Until = window.until
+
+	args := []string{"logs", pod, "-n", namespace, "-c", container}
+	if previous {
+		args = append(args, "-p")
+	}
+	window.timestamps = true
+	args = append(args, window.kubectlArgs()...)
+
+	lines := 0
+	var writeErr error
+	err = streamKubectlLines(ctx, pod+"/"+container, args, func(line string) {
+		out, ok := pipeline.Process(line)
+		if !ok || writeErr != nil {
+			return
+		}
+		if _, writeErr = fmt.Fprintln(file, out); writeErr == nil {
+			lines++
+		}
+	})
+	if err != nil {
+		return lines, fmt.Errorf("kubectl error: %v", err)
+	}
+	return lines, writeErr
+}
+
+// writeTarGz archives the content of srcDir into a tar.gz rooted at prefix
+func writeTarGz(archivePath, srcDir, prefix string) error {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(prefix, rel))
+		if info.IsDir() {
+			header.Name = strings.TrimSuffix(header.Name, "/") + "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
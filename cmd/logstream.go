@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"github.com/stanzinofree/kcsi/pkg/logfilter"
+)
+
+// logsPollInterval is how often followed log streams look for new or removed pods
+const logsPollInterval = 5 * time.Second
+
+// logPrefixColors are assigned to pods by hashing their name
+var logPrefixColors = []string{colorCyan, colorGreen, colorYellow, colorBlue, colorPurple, colorRed}
+
+// logTarget is a single container whose logs are streamed
+type logTarget struct {
+	Pod       string
+	Container string
+}
+
+func (t logTarget) prefix() string {
+	return fmt.Sprintf("[%s/%s]", t.Pod, t.Container)
+}
+
+// logStream is a running kubectl logs process
+type logStream struct {
+	cancel context.CancelFunc
+}
+
+// logAggregator streams the logs of every container of the pods matching a
+// selector concurrently. When following, it periodically re-lists the pods
+// to pick up new ones and stop streams of terminated ones.
+type logAggregator struct {
+	namespace     string
+	selector      string
+	podName       string
+	container     string
+	allContainers bool
+	follow        bool
+	tail          int64
+	pipeline      *logfilter.Pipeline
+	windowArgs    []string
+
+	// outMu serializes writes to stdout so lines of different streams don't interleave
+	outMu sync.Mutex
+
+	// mu guards active and ended
+	mu     sync.Mutex
+	active map[logTarget]*logStream
+	ended  map[logTarget]time.Time
+	wg     sync.WaitGroup
+}
+
+func newLogAggregator(namespace, selector, podName string) *logAggregator {
+	return &logAggregator{
+		namespace: namespace,
+		selector:  selector,
+		podName:   podName,
+		active:    map[logTarget]*logStream{},
+		ended:     map[logTarget]time.Time{},
+	}
+}
+
+// run streams logs until all streams end, or until ctx is cancelled when following
+func (a *logAggregator) run(ctx context.Context) error {
+	started, err := a.sync(ctx)
+	if err != nil {
+		return err
+	}
+	if started == 0 {
+		return fmt.Errorf("no running containers found to stream logs from")
+	}
+
+	if !a.follow {
+		a.wg.Wait()
+		return nil
+	}
+
+	ticker := time.NewTicker(logsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.wg.Wait()
+			return nil
+		case <-ticker.C:
+			// Listing failures are transient during a rollout, retry on the next tick
+			a.sync(ctx)
+		}
+	}
+}
+
+// sync starts streams for new containers and stops streams of pods that are
+// gone or terminating. It returns the number of streams started.
+func (a *logAggregator) sync(ctx context.Context) (int, error) {
+	pods, err := a.listPods()
+	if err != nil {
+		return 0, err
+	}
+
+	desired := map[logTarget]bool{}
+	for _, pod := range pods {
+		for _, target := range a.podTargets(pod) {
+			desired[target] = true
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for target, stream := range a.active {
+		if !desired[target] {
+			stream.cancel()
+			delete(a.active, target)
+			a.notice(fmt.Sprintf("- %s stream closed (pod terminated)", target.prefix()))
+		}
+	}
+
+	started := 0
+	for target := range desired {
+		if _, ok := a.active[target]; ok {
+			continue
+		}
+		a.startStream(ctx, target)
+		started++
+	}
+
+	return started, nil
+}
+
+func (a *logAggregator) listPods() ([]kubernetes.Pod, error) {
+	if a.podName == "" {
+		return kubernetes.ListPods(a.namespace, a.selector)
+	}
+
+	pod, err := kubernetes.GetPod(a.namespace, a.podName)
+	if err != nil {
+		return nil, err
+	}
+	return []kubernetes.Pod{*pod}, nil
+}
+
+// podTargets returns the containers of a pod whose logs should be streamed
+func (a *logAggregator) podTargets(pod kubernetes.Pod) []logTarget {
+	if pod.Metadata.DeletionTimestamp != nil && a.follow {
+		return nil
+	}
+
+	running := map[string]bool{}
+	started := map[string]bool{}
+	for _, cs := range pod.Status.ContainerStatuses {
+		running[cs.Name] = cs.State.Running != nil
+		started[cs.Name] = cs.State.Waiting == nil || cs.RestartCount > 0
+	}
+
+	var targets []logTarget
+	for _, name := range a.selectContainers(pod) {
+		// Followed streams only attach to running containers, one-shot reads
+		// also cover completed ones
+		if (a.follow && running[name]) || (!a.follow && started[name]) {
+			targets = append(targets, logTarget{Pod: pod.Metadata.Name, Container: name})
+		}
+	}
+	return targets
+}
+
+// selectContainers returns the container names chosen by -c, --all-containers
+// or the pod's default container
+func (a *logAggregator) selectContainers(pod kubernetes.Pod) []string {
+	if a.container != "" {
+		return []string{a.container}
+	}
+	if a.allContainers {
+		names := make([]string, 0, len(pod.Spec.Containers))
+		for _, c := range pod.Spec.Containers {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+	if name := pod.Metadata.Annotations["kubectl.kubernetes.io/default-container"]; name != "" {
+		return []string{name}
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return []string{pod.Spec.Containers[0].Name}
+	}
+	return nil
+}
+
+// startStream runs kubectl logs for a target in the background. Must be called with mu held.
+func (a *logAggregator) startStream(ctx context.Context, target logTarget) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	current := &logStream{cancel: cancel}
+	a.active[target] = current
+
+	args := []string{"logs", target.Pod, "-c", target.Container}
+	if a.namespace != "" {
+		args = append(args, "-n", a.namespace)
+	}
+	if a.follow {
+		args = append(args, "-f")
+	}
+
+	// A restarted stream continues where the previous one ended
+	if endedAt, ok := a.ended[target]; ok {
+		args = append(args, "--since-time="+endedAt.UTC().Format(time.RFC3339))
+		if a.pipeline.Timestamped {
+			args = append(args, "--timestamps")
+		}
+		a.notice(fmt.Sprintf("+ %s stream resumed", target.prefix()))
+	} else {
+		if a.tail >= 0 {
+			args = append(args, fmt.Sprintf("--tail=%d", a.tail))
+		}
+		args = append(args, a.windowArgs...)
+		if a.follow {
+			a.notice(fmt.Sprintf("+ %s streaming", target.prefix()))
+		}
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer cancel()
+
+		if err := a.stream(streamCtx, target, args); err != nil && streamCtx.Err() == nil {
+			a.notice(fmt.Sprintf("! %s %v", target.prefix(), err))
+		}
+
+		a.mu.Lock()
+		a.ended[target] = time.Now()
+		if a.active[target] == current {
+			delete(a.active, target)
+		}
+		a.mu.Unlock()
+	}()
+}
+
+// stream copies the output of a kubectl logs command line by line with a colored prefix
+func (a *logAggregator) stream(ctx context.Context, target logTarget, args []string) error {
+	prefix := colorize(podColor(target.Pod), target.prefix())
+	return streamKubectlLines(ctx, target.Pod+"/"+target.Container, args, func(line string) {
+		a.emit(prefix, line)
+	})
+}
+
+// streamKubectlLines runs kubectl and calls onLine for every line of its
+// output. source names the pod or container read, for errors.
+func streamKubectlLines(ctx context.Context, source string, args []string, onLine func(string)) error {
+	cmd := kubernetes.KubectlCommand(ctx, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		onLine(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		// Stop kubectl and drain the pipe: with -f it would otherwise block
+		// writing output nobody reads, and Wait would never return
+		go io.Copy(io.Discard, stdout)
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("failed to read output of %s: %w", source, err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// emit writes a single prefixed log line that passed the log pipeline
+func (a *logAggregator) emit(prefix, line string) {
+	line, ok := a.pipeline.Process(line)
+	if !ok {
+		return
+	}
+
+	a.outMu.Lock()
+	defer a.outMu.Unlock()
+	fmt.Fprintf(os.Stdout, "%s %s\n", prefix, line)
+}
+
+// notice writes a status message about the streams to stderr
+func (a *logAggregator) notice(message string) {
+	a.outMu.Lock()
+	defer a.outMu.Unlock()
+	fmt.Fprintln(os.Stderr, colorize(colorGray, message))
+}
+
+// podColor picks a stable prefix color for a pod
+func podColor(pod string) string {
+	h := fnv.New32a()
+	h.Write([]byte(pod))
+	return logPrefixColors[h.Sum32()%uint32(len(logPrefixColors))]
+}
+
+// resolveLogSelector turns a type/name reference such as deploy/web or svc/web
+// into the label selector of its pods. A pod reference returns the pod name instead.
+func resolveLogSelector(ref, namespace string) (selector, podName string, err error) {
+	resourceType, name, _ := strings.Cut(ref, "/")
+	if name == "" {
+		return "", "", fmt.Errorf("invalid resource reference '%s', expected type/name (e.g. deploy/web)", ref)
+	}
+
+	switch resourceType {
+	case "pod", "pods", "po":
+		return "", name, nil
+	case "service", "services", "svc":
+		svc, err := kubernetes.GetObject("service", name, namespace)
+		if err != nil {
+			return "", "", err
+		}
+		if svc == nil {
+			return "", "", fmt.Errorf("service '%s' not found", name)
+		}
+		spec, _ := svc["spec"].(map[string]interface{})
+		rawSelector, _ := spec["selector"].(map[string]interface{})
+		if len(rawSelector) == 0 {
+			return "", "", fmt.Errorf("service '%s' has no pod selector", name)
+		}
+		labels := map[string]string{}
+		for key, value := range rawSelector {
+			labels[key] = fmt.Sprintf("%v", value)
+		}
+		return kubernetes.LabelSelector{MatchLabels: labels}.String(), "", nil
+	case "deployment", "deployments", "deploy",
+		"statefulset", "statefulsets", "sts",
+		"daemonset", "daemonsets", "ds",
+		"replicaset", "replicasets", "rs",
+		"job", "jobs":
+		workload, err := kubernetes.GetWorkload(resourceType, name, namespace)
+		if err != nil {
+			return "", "", err
+		}
+		return workload.Spec.Selector.String(), "", nil
+	}
+
+	return "", "", fmt.Errorf("unsupported resource type '%s' for logs", resourceType)
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/completion"
+	kcsicontext "github.com/stanzinofree/kcsi/pkg/context"
+	"github.com/stanzinofree/kcsi/pkg/drain"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// drainRetryInterval is the pause between eviction attempts of pods blocked by a PDB
+const drainRetryInterval = 5 * time.Second
+
+var nodeCordonCmd = &cobra.Command{
+	Use:   "cordon [node]",
+	Short: "Mark a node as unschedulable",
+	Long: `Mark a node as unschedulable. Running pods are not affected.
+
+Examples:
+  kcsi node cordon worker-3`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: nodeNameCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNodeSchedulable(cmd, args[0], false)
+	},
+}
+
+var nodeUncordonCmd = &cobra.Command{
+	Use:   "uncordon [node]",
+	Short: "Mark a node as schedulable again",
+	Long: `Allow new pods to be scheduled on a cordoned node.
+
+Examples:
+  kcsi node uncordon worker-3`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: nodeNameCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNodeSchedulable(cmd, args[0], true)
+	},
+}
+
+var nodeDrainCmd = &cobra.Command{
+	Use:   "drain [node]",
+	Short: "Cordon a node and evict its pods",
+	Long: `Cordon a node and evict its pods through the eviction API, which honours
+PodDisruptionBudgets.
+
+Before asking for confirmation the drain shows the pods it will evict, the
+pods it leaves alone (DaemonSet, static and finished pods) and the
+PodDisruptionBudgets that currently allow no disruption. Evictions blocked by
+a budget are retried until --timeout.
+
+Progress is recorded in ~/.kcsi/drains. If the drain is interrupted, run the
+same command again to resume it with the original options.
+
+Examples:
+  kcsi node drain worker-3
+  kcsi node drain worker-3 --dry-run
+  kcsi node drain worker-3 --delete-emptydir-data --timeout 15m`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: nodeNameCompletion,
+	RunE:              runNodeDrain,
+}
+
+func init() {
+	nodesCmd.AddCommand(nodeCordonCmd)
+	nodesCmd.AddCommand(nodeUncordonCmd)
+	nodesCmd.AddCommand(nodeDrainCmd)
+
+	for _, c := range []*cobra.Command{nodeCordonCmd, nodeUncordonCmd, nodeDrainCmd} {
+		c.Flags().BoolP("yes", "y", false, FlagDescSkipConfirm)
+	}
+
+	nodeDrainCmd.Flags().Bool("force", false, "Also evict pods not managed by a controller (they are not recreated)")
+	nodeDrainCmd.Flags().Bool("delete-emptydir-data", false, "Also evict pods using emptyDir volumes (their data is lost)")
+	nodeDrainCmd.Flags().Duration("timeout", 10*time.Minute, "Give up when pods are still blocked or terminating after this duration")
+	nodeDrainCmd.Flags().Bool("dry-run", false, "Only show what the drain would do")
+}
+
+func nodeNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.NodeCompletion(cmd, args, toComplete)
+}
+
+// runNodeSchedulable cordons or uncordons a node
+func runNodeSchedulable(cmd *cobra.Command, name string, schedulable bool) error {
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	node, err := kubernetes.GetNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %v", name, err)
+	}
+
+	action, question := "cordon", fmt.Sprintf("Cordon node %s? New pods will not be scheduled on it.", name)
+	if schedulable {
+		action, question = "uncordon", fmt.Sprintf("Uncordon node %s? New pods may be scheduled on it.", name)
+	}
+
+	if node.Spec.Unschedulable != schedulable {
+		fmt.Printf("ℹ️  Node %s is already %s\n", name, nodeStatus(*node))
+		return nil
+	}
+
+	proceed, err := confirmClusterChange(question, yes)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Printf("%s cancelled.\n", strings.ToUpper(action[:1])+action[1:])
+		return nil
+	}
+
+	output, err := kubernetes.ExecuteKubectl(action, name)
+	if err != nil {
+		return fmt.Errorf("failed to %s node %s: %v", action, name, err)
+	}
+	fmt.Print(output)
+	return nil
+}
+
+func runNodeDrain(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	yes, _ := cmd.Flags().GetBool("yes")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	opts := drain.Options{}
+	opts.Force, _ = cmd.Flags().GetBool("force")
+	opts.DeleteEmptyDirData, _ = cmd.Flags().GetBool("delete-emptydir-data")
+
+	kcsiDir, err := kcsicontext.GetKcsiDir()
+	if err != nil {
+		return err
+	}
+	contextName, _ := kcsicontext.GetCurrentContextName()
+	statePath := drain.StatePath(kcsiDir, contextName, name)
+
+	state, err := drain.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		fmt.Printf("↻ Resuming drain of node %s started %s (%d pods evicted so far)\n",
+			name, state.StartedAt.Local().Format("2006-01-02 15:04:05"), len(state.Evicted))
+		opts.Force = opts.Force || state.Force
+		opts.DeleteEmptyDirData = opts.DeleteEmptyDirData || state.DeleteEmptyDirData
+		fmt.Println()
+	}
+
+	node, err := kubernetes.GetNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %v", name, err)
+	}
+	pods, err := kubernetes.ListPodsOnNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to list pods on node %s: %v", name, err)
+	}
+	pdbs, err := kubernetes.ListPDBs("")
+	if err != nil {
+		return fmt.Errorf("failed to list pod disruption budgets: %v", err)
+	}
+
+	plan := drain.NewPlan(pods, pdbs, opts)
+	printDrainPlan(node, plan)
+
+	if len(plan.Refused) > 0 {
+		return fmt.Errorf("cannot drain node %s: %d pods need --force or --delete-emptydir-data", name, len(plan.Refused))
+	}
+	if dryRun {
+		fmt.Println("Dry run: no changes made")
+		return nil
+	}
+
+	proceed, err := confirmClusterChange(fmt.Sprintf("Drain node %s (cordon and evict %d pods)?", name, len(plan.Evict)), yes)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Println("Drain cancelled.")
+		return nil
+	}
+
+	if !node.Spec.Unschedulable {
+		if _, err := kubernetes.ExecuteKubectl("cordon", name); err != nil {
+			return fmt.Errorf("failed to cordon node %s: %v", name, err)
+		}
+		fmt.Printf("✓ Node %s cordoned\n", name)
+	}
+
+	if state == nil {
+		state = &drain.State{Node: name, Context: contextName, StartedAt: time.Now().UTC()}
+	}
+	state.Force = opts.Force
+	state.DeleteEmptyDirData = opts.DeleteEmptyDirData
+	state.Pending = nil
+	for _, pod := range plan.Evict {
+		state.Pending = append(state.Pending, drain.PodKey(pod))
+	}
+	saveState := func() error {
+		state.UpdatedAt = time.Now().UTC()
+		return state.Save(statePath)
+	}
+	if err := saveState(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	deadline := time.Now().Add(timeout)
+	evicted, err := evictDrainPods(ctx, plan.Evict, len(state.Evicted), deadline, func(key string) error {
+		state.MarkEvicted(key)
+		return saveState()
+	})
+	if err == nil {
+		err = waitForPodsGone(ctx, evicted, deadline)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Println()
+			fmt.Printf("⏸  Drain interrupted, progress saved to %s\n", statePath)
+			fmt.Printf("   Run 'kcsi node drain %s' again to resume\n", name)
+			return fmt.Errorf("drain of node %s interrupted", name)
+		}
+		fmt.Printf("Progress saved to %s, run the drain again to resume\n", statePath)
+		return err
+	}
+
+	if err := drain.RemoveState(statePath); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Node %s drained (%d pods evicted)\n", name, len(state.Evicted))
+	fmt.Printf("Run 'kcsi node uncordon %s' once maintenance is done\n", name)
+	return nil
+}
+
+// printDrainPlan shows what the drain will evict, leave alone and wait for
+func printDrainPlan(node *kubernetes.Node, plan drain.Plan) {
+	fmt.Printf("Node %s: %s\n\n", node.Metadata.Name, nodeStatus(*node))
+
+	if len(plan.Evict) > 0 {
+		fmt.Println(colorize(colorBold, fmt.Sprintf("Pods to evict (%d):", len(plan.Evict))))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "  NAMESPACE\tPOD\tOWNER")
+		for _, pod := range plan.Evict {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", pod.Metadata.Namespace, pod.Metadata.Name, drain.Owner(pod))
+		}
+		w.Flush()
+		fmt.Println()
+	} else {
+		fmt.Println("No pods to evict")
+		fmt.Println()
+	}
+
+	if len(plan.Ignored) > 0 {
+		counts := map[string]int{}
+		var reasons []string
+		for _, ignored := range plan.Ignored {
+			if counts[ignored.Reason] == 0 {
+				reasons = append(reasons, ignored.Reason)
+			}
+			counts[ignored.Reason]++
+		}
+		var parts []string
+		for _, reason := range reasons {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[reason], reason))
+		}
+		fmt.Println(colorize(colorGray, "Left on the node: "+strings.Join(parts, ", ")))
+		fmt.Println()
+	}
+
+	if len(plan.Refused) > 0 {
+		fmt.Println(colorize(colorRed, fmt.Sprintf("Pods that block the drain (%d):", len(plan.Refused))))
+		for _, refused := range plan.Refused {
+			fmt.Printf("  %s: %s\n", drain.PodKey(refused.Pod), refused.Reason)
+		}
+		fmt.Println()
+	}
+
+	if len(plan.Blockers) > 0 {
+		fmt.Println(colorize(colorYellow, "⚠️  PodDisruptionBudgets allowing no disruption right now:"))
+		for _, blocker := range plan.Blockers {
+			pdb := blocker.PDB
+			fmt.Printf("  %s/%s (healthy %d, desired %d) holds %s\n",
+				pdb.Metadata.Namespace, pdb.Metadata.Name, pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy,
+				strings.Join(blocker.Pods, ", "))
+		}
+		fmt.Println("  Their evictions are retried until the budget allows them or --timeout expires.")
+		fmt.Println()
+	}
+}
+
+// evictDrainPods evicts pods, retrying those blocked by a PDB until the
+// deadline. done is the number of pods evicted by an earlier run, shown in
+// the progress counter. onEvicted is called after each accepted eviction.
+func evictDrainPods(ctx context.Context, pods []kubernetes.Pod, done int, deadline time.Time, onEvicted func(key string) error) ([]kubernetes.Pod, error) {
+	total := done + len(pods)
+	pending := pods
+	var evicted []kubernetes.Pod
+	reported := map[string]bool{}
+
+	for len(pending) > 0 {
+		var blocked []kubernetes.Pod
+		for _, pod := range pending {
+			key := drain.PodKey(pod)
+			err := kubernetes.EvictPod(ctx, pod.Metadata.Namespace, pod.Metadata.Name)
+			if ctx.Err() != nil {
+				return evicted, ctx.Err()
+			}
+			if errors.Is(err, kubernetes.ErrEvictionBlocked) {
+				if !reported[key] {
+					fmt.Printf("  %s %s blocked by a PodDisruptionBudget, retrying\n", colorize(colorYellow, "⏸"), key)
+					reported[key] = true
+				}
+				blocked = append(blocked, pod)
+				continue
+			}
+			if err != nil {
+				return evicted, fmt.Errorf("failed to evict %s: %v", key, err)
+			}
+
+			done++
+			evicted = append(evicted, pod)
+			fmt.Printf("  [%d/%d] %s evicted %s\n", done, total, colorize(colorGreen, "✓"), key)
+			if err := onEvicted(key); err != nil {
+				return evicted, err
+			}
+		}
+
+		pending = blocked
+		if len(pending) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return evicted, fmt.Errorf("%d pods are still blocked by PodDisruptionBudgets", len(pending))
+		}
+		select {
+		case <-ctx.Done():
+			return evicted, ctx.Err()
+		case <-time.After(drainRetryInterval):
+		}
+	}
+
+	return evicted, nil
+}
+
+// waitForPodsGone waits until the evicted pods are deleted. A pod recreated
+// with the same name (statefulsets) has a new UID and counts as gone.
+func waitForPodsGone(ctx context.Context, pods []kubernetes.Pod, deadline time.Time) error {
+	remaining := pods
+	lastLine := ""
+	for {
+		var still []kubernetes.Pod
+		for _, pod := range remaining {
+			current, err := kubernetes.GetObject("pod", pod.Metadata.Name, pod.Metadata.Namespace)
+			if err != nil {
+				still = append(still, pod)
+				continue
+			}
+			if current == nil {
+				continue
+			}
+			if metadata, ok := current["metadata"].(map[string]interface{}); ok && metadata["uid"] != pod.Metadata.UID {
+				continue
+			}
+			still = append(still, pod)
+		}
+		remaining = still
+
+		if len(remaining) == 0 {
+			if lastLine != "" {
+				finishProgressLine()
+			}
+			return nil
+		}
+		lastLine = renderProgressLine(fmt.Sprintf("Waiting for %d evicted pods to terminate...", len(remaining)), lastLine)
+
+		if time.Now().After(deadline) {
+			finishProgressLine()
+			return fmt.Errorf("%d evicted pods did not terminate in time", len(remaining))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rolloutPollInterval):
+		}
+	}
+}
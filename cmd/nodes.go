@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/capacity"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// nodeRoleLabelPrefix is the prefix of node role labels (node-role.kubernetes.io/<role>)
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+var nodesCmd = &cobra.Command{
+	Use:     "nodes",
+	Aliases: []string{"node"},
+	Short:   "Inspect and manage cluster nodes",
+	Long:    "Inspect node capacity and health",
+}
+
+var nodesOverviewCmd = &cobra.Command{
+	Use:   "overview",
+	Short: "Show node health, taints and requested resources",
+	Long: `Show one line per node with its status, pressure conditions, taints,
+allocatable CPU and memory, the requests and limits of the pods scheduled on
+it (as a percentage of allocatable) and its pod count.
+
+Requests and limits are summed from the pod specs, not from metrics. NotReady
+nodes are highlighted in red and cordoned nodes in yellow.
+
+Examples:
+  kcsi nodes overview
+  kcsi nodes overview --sort cpu
+  kcsi nodes overview --sort pods`,
+	Args: cobra.NoArgs,
+	RunE: runNodesOverview,
+}
+
+var nodesSort string
+
+func init() {
+	rootCmd.AddCommand(nodesCmd)
+	nodesCmd.AddCommand(nodesOverviewCmd)
+
+	nodesOverviewCmd.Flags().StringVar(&nodesSort, "sort", capacity.SortName, "Sort by name, or by descending cpu, mem (requested %) or pods")
+	nodesOverviewCmd.RegisterFlagCompletionFunc("sort", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return capacity.SortKeys, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runNodesOverview(_ *cobra.Command, _ []string) error {
+	nodes, err := kubernetes.ListNodes()
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := kubernetes.ListPods("", "")
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	summaries := capacity.SummarizeNodes(nodes, pods)
+	if !capacity.SortNodes(summaries, nodesSort) {
+		return fmt.Errorf("unknown sort key '%s' (valid: %s)", nodesSort, strings.Join(capacity.SortKeys, ", "))
+	}
+
+	if len(summaries) == 0 {
+		fmt.Println("No nodes found")
+		return nil
+	}
+
+	table := newColoredTable("NAME", "STATUS", "ROLES", "CPU", "CPU REQ", "CPU LIM", "MEMORY", "MEM REQ", "MEM LIM", "PODS", "CONDITIONS", "TAINTS")
+	notReady, cordoned := 0, 0
+	for _, s := range summaries {
+		color := ""
+		if s.Node.Spec.Unschedulable {
+			color = colorYellow
+			cordoned++
+		}
+		if !s.Node.IsReady() {
+			color = colorRed
+			notReady++
+		}
+
+		pods := fmt.Sprintf("%d", s.Pods)
+		if s.MaxPods > 0 {
+			pods = fmt.Sprintf("%d/%d", s.Pods, s.MaxPods)
+		}
+
+		table.addRow(color,
+			s.Node.Metadata.Name,
+			nodeStatus(s.Node),
+			nodeRoles(s.Node),
+			kubernetes.FormatCPU(s.Allocatable.CPU),
+			formatPercent(s.CPURequestPercent()),
+			formatPercent(s.CPULimitPercent()),
+			kubernetes.FormatMemory(s.Allocatable.Memory),
+			formatPercent(s.MemoryRequestPercent()),
+			formatPercent(s.MemoryLimitPercent()),
+			pods,
+			nodeConditions(s.Node),
+			nodeTaints(s.Node),
+		)
+	}
+	table.print()
+
+	fmt.Println()
+	fmt.Printf("%d nodes", len(summaries))
+	if notReady > 0 {
+		fmt.Printf(", %s", colorize(colorRed, fmt.Sprintf("%d NotReady", notReady)))
+	}
+	if cordoned > 0 {
+		fmt.Printf(", %s", colorize(colorYellow, fmt.Sprintf("%d cordoned", cordoned)))
+	}
+	fmt.Println()
+	fmt.Println("Limits above 100% mean the node is overcommitted")
+
+	return nil
+}
+
+// nodeStatus renders the node status the way kubectl does (Ready,SchedulingDisabled)
+func nodeStatus(node kubernetes.Node) string {
+	status := "NotReady"
+	if node.IsReady() {
+		status = "Ready"
+	}
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// nodeRoles returns the roles from node-role.kubernetes.io labels
+func nodeRoles(node kubernetes.Node) string {
+	var roles []string
+	for label := range node.Metadata.Labels {
+		if role := strings.TrimPrefix(label, nodeRoleLabelPrefix); role != label && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return "<none>"
+	}
+	sort.Strings(roles)
+	return strings.Join(roles, ",")
+}
+
+// nodeConditions lists the pressure conditions set on the node
+func nodeConditions(node kubernetes.Node) string {
+	var names []string
+	for _, cond := range node.PressureConditions() {
+		names = append(names, cond.Type)
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
+
+// nodeTaints lists the node taints, skipping the unschedulable taint already shown in the status
+func nodeTaints(node kubernetes.Node) string {
+	var taints []string
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == "node.kubernetes.io/unschedulable" {
+			continue
+		}
+		taints = append(taints, taint.String())
+	}
+	if len(taints) == 0 {
+		return "-"
+	}
+	return strings.Join(taints, ",")
+}
+
+// formatPercent renders a percentage without decimals
+func formatPercent(percent float64) string {
+	return fmt.Sprintf("%.0f%%", percent)
+}
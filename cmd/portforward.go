@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/stanzinofree/kcsi/pkg/completion"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"github.com/stanzinofree/kcsi/pkg/portforward"
 )
 
 var (
@@ -15,17 +22,54 @@ var (
 )
 
 var portForwardCmd = &cobra.Command{
-	Use:   "port-forward [pod-name] [local-port:remote-port]",
-	Short: "Forward one or more local ports to a pod",
-	Long: `Forward one or more local ports to a pod with intelligent autocompletion.
+	Use:     "port-forward [target] [local-port:remote-port]...",
+	Aliases: []string{"pf"},
+	Short:   "Forward local ports to a pod, service or workload",
+	Long: `Forward one or more local ports to a pod, service or workload with intelligent autocompletion.
+
+The target is a pod name, svc/<name>, deploy/<name> or sts/<name>. Services
+and workloads are resolved to one of their running pods, preferring ready
+ones. For services the remote port is a service port (number or name) and
+is translated to the container port it targets, including named target
+ports. For pods and workloads it is a container port number or name.
+
+Several mappings can be forwarded at once. Leave the local port out
+(":remote") to pick a free one. The actual bindings are printed before
+forwarding starts. Use --address to listen on other interfaces than
+localhost.
+
+When kubectl port-forward exits, because the pod restarted or the
+connection dropped, the target is resolved again (a new pod behind the
+service or workload) and the same local ports are forwarded to it, with
+a backoff from 1s up to 30s between attempts. Reconnects are logged with
+timestamps until Ctrl+C. Use --no-reconnect to exit instead.
+
+The names save, up, down and list (ls) are the profile subcommands of
+kcsi pf: forward a pod with one of these names as pod/<name>.
 
 Examples:
   # Forward local port 8080 to pod port 80
   kcsi port-forward -n default my-pod 8080:80
-  
+
+  # Forward to the port named http of a service
+  kcsi port-forward -n production svc/web 8080:http
+
+  # Forward to a deployment or statefulset
+  kcsi port-forward -n production deploy/api 9090:metrics
+  kcsi port-forward -n production sts/postgres 5432
+
+  # Forward several ports, picking a free local port for metrics
+  kcsi port-forward -n production svc/web 8080:http :metrics
+
+  # Forward to a pod named like a profile subcommand
+  kcsi port-forward -n default pod/list 8080:80
+
+  # Listen on every interface
+  kcsi port-forward -n production svc/web 8080:http --address 0.0.0.0
+
   # Forward local port 80 to pod port 8080 (requires root for ports < 1024)
   sudo kcsi port-forward -n production web-server 80:8080`,
-	Args:                  cobra.ExactArgs(2),
+	Args:                  cobra.MinimumNArgs(2),
 	ValidArgsFunction:     portForwardCompletion,
 	DisableFlagsInUseLine: true,
 	RunE:                  runPortForward,
@@ -34,65 +78,368 @@ Examples:
 func init() {
 	rootCmd.AddCommand(portForwardCmd)
 	portForwardCmd.Flags().StringVarP(&portForwardNamespace, "namespace", "n", "", FlagDescNamespace)
+	portForwardCmd.Flags().Bool("no-reconnect", false, "Exit when kubectl port-forward exits instead of reconnecting")
+	portForwardCmd.Flags().String("address", "localhost", "Addresses to listen on (comma separated, localhost or IP addresses)")
 	portForwardCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
+	portForwardCmd.RegisterFlagCompletionFunc("address", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"localhost", "127.0.0.1", "0.0.0.0"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// portForwardScope returns the namespace targets are resolved in
+func portForwardScope() string {
+	return kubernetes.ResolveNamespace(portForwardNamespace)
 }
 
 func portForwardCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) == 0 {
-		// First arg: pod name
+		return portForwardTargetCompletion(cmd, args, toComplete)
+	}
+	return portForwardPortCompletion(args[0])
+}
+
+// portForwardTargetCompletion completes pod names, or svc/, deploy/ and sts/
+// names once a type prefix is typed
+func portForwardTargetCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	resourceType, _, found := strings.Cut(toComplete, "/")
+	if !found {
 		return completion.PodCompletion(cmd, args, toComplete)
 	}
-	// Second arg: port mapping (no completion)
-	return nil, cobra.ShellCompDirectiveNoFileComp
+
+	namespace := portForwardScope()
+	var names []string
+	var err error
+	switch resourceType {
+	case "svc", "service":
+		names, err = kubernetes.GetServices(namespace)
+	case "deploy", "deployment":
+		names, err = kubernetes.GetDeployments(namespace)
+	case "sts", "statefulset":
+		names, err = kubernetes.GetStatefulSets(namespace)
+	case "pod", "po":
+		names, err = kubernetes.GetPods(namespace)
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := make([]string, 0, len(names))
+	for _, name := range names {
+		completions = append(completions, resourceType+"/"+name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// portForwardPortCompletion suggests port mappings from the service ports
+// of a service target or the container ports of the target pod
+func portForwardPortCompletion(ref string) ([]string, cobra.ShellCompDirective) {
+	target, err := portforward.ParseTarget(ref)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	pod, svc, err := resolveTargetPod(target, portForwardScope())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var suggestions []string
+	if svc != nil {
+		for _, sp := range svc.Spec.Ports {
+			containerPort, _, err := portforward.ServicePort(*svc, *pod, strconv.Itoa(sp.Port))
+			description := portforward.ServicePortLabel(sp)
+			if err == nil {
+				description += fmt.Sprintf(" → container port %d", containerPort)
+			}
+			suggestions = append(suggestions, fmt.Sprintf("%d:%d\t%s", sp.Port, sp.Port, description))
+		}
+		return suggestions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			description := container.Name
+			if p.Name != "" {
+				description += "/" + p.Name
+			}
+			suggestions = append(suggestions, fmt.Sprintf("%d:%d\t%s", p.ContainerPort, p.ContainerPort, description))
+		}
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// resolveTargetPod returns the pod a target forwards to, and the service
+// for service targets
+func resolveTargetPod(target portforward.Target, namespace string) (*kubernetes.Pod, *kubernetes.Service, error) {
+	var selector string
+	var svc *kubernetes.Service
+
+	switch target.Kind {
+	case portforward.KindPod:
+		pod, err := kubernetes.GetPod(namespace, target.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get pod %s: %v", target.Name, err)
+		}
+		return pod, nil, nil
+	case portforward.KindService:
+		var err error
+		svc, err = kubernetes.GetService(target.Name, namespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get service %s: %v", target.Name, err)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return nil, nil, fmt.Errorf("service %s has no pod selector", target.Name)
+		}
+		selector = kubernetes.LabelSelector{MatchLabels: svc.Spec.Selector}.String()
+	default:
+		workload, err := kubernetes.GetWorkload(target.Kind, target.Name, namespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get %s: %v", target, err)
+		}
+		selector = workload.Spec.Selector.String()
+	}
+
+	pods, err := kubernetes.ListPods(namespace, selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods of %s: %v", target, err)
+	}
+	pod, err := portforward.SelectPod(pods)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", target, err)
+	}
+	return &pod, svc, nil
+}
+
+// resolveRemotePort translates the remote part of a mapping to a container
+// port of the pod, through the service ports for service targets
+func resolveRemotePort(pod *kubernetes.Pod, svc *kubernetes.Service, remote string) (int, string, error) {
+	if svc != nil {
+		return portforward.ServicePort(*svc, *pod, remote)
+	}
+	return portforward.ContainerPort(*pod, remote)
+}
+
+// portBinding is a mapping resolved to a local and a container port
+type portBinding struct {
+	Local      int
+	Remote     int
+	RemoteName string
+	Requested  string
+}
+
+// resolveBindings resolves the remote side of every mapping against a pod,
+// using the local ports already assigned to the mappings
+func resolveBindings(pod *kubernetes.Pod, svc *kubernetes.Service, mappings []portforward.Mapping, locals []int) ([]portBinding, error) {
+	bindings := make([]portBinding, 0, len(mappings))
+	for i, mapping := range mappings {
+		remotePort, portName, err := resolveRemotePort(pod, svc, mapping.Remote)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, portBinding{Local: locals[i], Remote: remotePort, RemoteName: portName, Requested: mapping.Remote})
+	}
+	return bindings, nil
 }
 
-func runPortForward(_ *cobra.Command, args []string) error {
-	podName := args[0]
-	portMapping := args[1]
+// assignLocalPorts checks the requested local ports and picks a free port
+// for every mapping without one. Ports in taken are already assigned to
+// other forwards of the same run; retry is the command suggested with sudo.
+func assignLocalPorts(mappings []portforward.Mapping, addresses []string, taken map[int]bool, retry string) ([]int, error) {
+	locals := make([]int, len(mappings))
+	for i, mapping := range mappings {
+		if mapping.Local == 0 {
+			continue
+		}
+		// Check if running as root for privileged ports
+		if mapping.Local < 1024 && os.Geteuid() != 0 {
+			return nil, fmt.Errorf("local port %d requires root privileges (ports < 1024)\nTry: sudo %s",
+				mapping.Local, retry)
+		}
+		if taken[mapping.Local] {
+			return nil, fmt.Errorf("local port %d is already used by another forward", mapping.Local)
+		}
+		// Check if local port is already in use
+		for _, address := range addresses {
+			if isPortInUse(address, mapping.Local) {
+				return nil, fmt.Errorf("local port %d is already in use on %s\nPlease choose a different port, use :%s to pick a free one, or stop the process using it",
+					mapping.Local, address, mapping.Remote)
+			}
+		}
+		locals[i] = mapping.Local
+		taken[mapping.Local] = true
+	}
+
+	for i, mapping := range mappings {
+		if mapping.Local != 0 {
+			continue
+		}
+		port, err := pickFreePort(addresses, taken)
+		if err != nil {
+			return nil, err
+		}
+		locals[i] = port
+		taken[port] = true
+	}
+	return locals, nil
+}
+
+func runPortForward(cmd *cobra.Command, args []string) error {
+	target, err := portforward.ParseTarget(args[0])
+	if err != nil {
+		return err
+	}
+	mappings, err := portforward.ParseMappings(args[1:])
+	if err != nil {
+		return err
+	}
+	addressFlag, _ := cmd.Flags().GetString("address")
+	addresses, err := portforward.ParseAddresses(addressFlag)
+	if err != nil {
+		return err
+	}
+	namespace := portForwardScope()
+
+	retry := "kcsi port-forward -n " + namespace + " " + strings.Join(args, " ")
+	locals, err := assignLocalPorts(mappings, addresses, map[int]bool{}, retry)
+	if err != nil {
+		return err
+	}
 
-	// Parse port mapping (format: localPort:remotePort)
-	var localPort, remotePort int
-	_, err := fmt.Sscanf(portMapping, "%d:%d", &localPort, &remotePort)
+	pod, svc, err := resolveTargetPod(target, namespace)
+	if err != nil {
+		return err
+	}
+	bindings, err := resolveBindings(pod, svc, mappings, locals)
 	if err != nil {
-		return fmt.Errorf("invalid port format '%s', expected format: localPort:remotePort (e.g., 8080:80)", portMapping)
+		return err
+	}
+
+	printPortBindings(target, pod.Metadata.Name, addresses, bindings)
+
+	noReconnect, _ := cmd.Flags().GetBool("no-reconnect")
+	if noReconnect {
+		fmt.Printf("Press Ctrl+C to stop port forwarding\n\n")
+		return kubernetes.ExecuteKubectlInteractive(portForwardArgs(namespace, pod.Metadata.Name, addresses, bindings)...)
 	}
 
-	// Validate ports
-	if localPort < 1 || localPort > 65535 {
-		return fmt.Errorf("invalid local port %d, must be between 1 and 65535", localPort)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Press Ctrl+C to stop port forwarding (reconnects automatically)\n\n")
+	run := supervisedForward(target, namespace, mappings, locals, addresses, nil)
+	portforward.Supervise(ctx, run, portforward.DefaultBackoff, func(e portforward.Event) {
+		logPortForwardEvent(target.String(), e)
+	})
+	fmt.Println("\nPort forwarding stopped")
+	return nil
+}
+
+// supervisedForward returns a run function that re-resolves the target pod
+// on every attempt and forwards the same local ports to it. resolved, if
+// set, is called with the bindings of every attempt.
+func supervisedForward(target portforward.Target, namespace string, mappings []portforward.Mapping, locals []int, addresses []string, resolved func(pod string, bindings []portBinding)) portforward.RunFunc {
+	return func(ctx context.Context, connected func(string)) error {
+		pod, svc, err := resolveTargetPod(target, namespace)
+		if err != nil {
+			return err
+		}
+		bindings, err := resolveBindings(pod, svc, mappings, locals)
+		if err != nil {
+			return err
+		}
+		if resolved != nil {
+			resolved(pod.Metadata.Name, bindings)
+		}
+
+		announced := false
+		err = streamKubectlLines(ctx, pod.Metadata.Name, portForwardArgs(namespace, pod.Metadata.Name, addresses, bindings), func(line string) {
+			if !announced && strings.HasPrefix(line, "Forwarding from") {
+				announced = true
+				connected(pod.Metadata.Name)
+			}
+		})
+		if err == nil {
+			err = fmt.Errorf("kubectl port-forward exited")
+		}
+		return err
 	}
-	if remotePort < 1 || remotePort > 65535 {
-		return fmt.Errorf("invalid remote port %d, must be between 1 and 65535", remotePort)
+}
+
+// logPortForwardEvent prints a timestamped line for a forward connecting or
+// exiting
+func logPortForwardEvent(label string, e portforward.Event) {
+	fmt.Println(formatPortForwardEvent(label, e, true))
+}
+
+// formatPortForwardEvent renders a forward event, colored if requested
+func formatPortForwardEvent(label string, e portforward.Event, colored bool) string {
+	paint := func(color, text string) string {
+		if !colored {
+			return text
+		}
+		return colorize(color, text)
+	}
+
+	timestamp := paint(colorGray, "["+e.Time.Format("15:04:05")+"]")
+	if e.Type == portforward.EventConnected {
+		return fmt.Sprintf("%s %s %s → pod %s", timestamp, paint(colorGreen, "✓ connected"), label, e.Pod)
 	}
 
-	// Check if running as root for privileged ports
-	if localPort < 1024 && os.Geteuid() != 0 {
-		return fmt.Errorf("local port %d requires root privileges (ports < 1024)\nTry: sudo kcsi port-forward -n %s %s %s",
-			localPort, portForwardNamespace, podName, portMapping)
+	reason := strings.TrimSpace(e.Err.Error())
+	if e.Pod != "" {
+		reason = fmt.Sprintf("pod %s: %s", e.Pod, reason)
 	}
+	return fmt.Sprintf("%s %s %s (%s), reconnecting in %s (attempt %d)",
+		timestamp, paint(colorYellow, "✗ disconnected"), label, reason, e.Delay, e.Attempt)
+}
 
-	// Check if local port is already in use
-	if isPortInUse(localPort) {
-		return fmt.Errorf("local port %d is already in use\nPlease choose a different port or stop the process using it", localPort)
+// portForwardArgs builds the kubectl port-forward arguments for a pod
+func portForwardArgs(namespace, pod string, addresses []string, bindings []portBinding) []string {
+	args := []string{"port-forward", "-n", namespace, "--address", strings.Join(addresses, ","), "pod/" + pod}
+	for _, b := range bindings {
+		args = append(args, fmt.Sprintf("%d:%d", b.Local, b.Remote))
 	}
+	return args
+}
 
-	// Build kubectl command
-	kubectlArgs := []string{"port-forward"}
-	if portForwardNamespace != "" {
-		kubectlArgs = append(kubectlArgs, "-n", portForwardNamespace)
+// printPortBindings prints the local addresses bound for each mapping
+func printPortBindings(target portforward.Target, pod string, addresses []string, bindings []portBinding) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "LOCAL\tTARGET\tPOD\tREMOTE")
+	for _, b := range bindings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", bindingLocal(addresses, b), target, pod, bindingRemote(b))
 	}
-	kubectlArgs = append(kubectlArgs, podName, portMapping)
+	w.Flush()
+	fmt.Println()
+}
 
-	fmt.Printf("Forwarding from 127.0.0.1:%d -> %s:%d\n", localPort, podName, remotePort)
-	fmt.Printf("Press Ctrl+C to stop port forwarding\n\n")
+// bindingLocal renders the local addresses of a binding
+func bindingLocal(addresses []string, b portBinding) string {
+	var locals []string
+	for _, address := range addresses {
+		locals = append(locals, net.JoinHostPort(address, strconv.Itoa(b.Local)))
+	}
+	return strings.Join(locals, ", ")
+}
 
-	return kubernetes.ExecuteKubectlInteractive(kubectlArgs...)
+// bindingRemote renders the container port of a binding with its name and
+// the port requested, when they differ
+func bindingRemote(b portBinding) string {
+	remote := strconv.Itoa(b.Remote)
+	if b.RemoteName != "" {
+		remote += "/" + b.RemoteName
+	}
+	if b.Requested != strconv.Itoa(b.Remote) && b.Requested != b.RemoteName {
+		remote += " (" + b.Requested + ")"
+	}
+	return remote
 }
 
-// isPortInUse checks if a local port is already in use
-func isPortInUse(port int) bool {
+// isPortInUse checks if a local port is already in use on an address
+func isPortInUse(address string, port int) bool {
 	// Try to listen on the port
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
 	if err != nil {
 		// If we can't listen, the port is in use
 		return true
@@ -101,3 +448,31 @@ func isPortInUse(port int) bool {
 	listener.Close()
 	return false
 }
+
+// pickFreePort asks the OS for a free port on the first address and checks
+// it is free on the others too
+func pickFreePort(addresses []string, taken map[int]bool) (int, error) {
+	for attempt := 0; attempt < 10; attempt++ {
+		listener, err := net.Listen("tcp", net.JoinHostPort(addresses[0], "0"))
+		if err != nil {
+			return 0, fmt.Errorf("failed to find a free local port on %s: %v", addresses[0], err)
+		}
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		if taken[port] {
+			continue
+		}
+		free := true
+		for _, address := range addresses[1:] {
+			if isPortInUse(address, port) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to find a local port free on %s", strings.Join(addresses, ", "))
+}
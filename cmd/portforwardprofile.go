@@ -0,0 +1,511 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/completion"
+	kcsicontext "github.com/stanzinofree/kcsi/pkg/context"
+	"github.com/stanzinofree/kcsi/pkg/portforward"
+)
+
+var portForwardSaveCmd = &cobra.Command{
+	Use:   "save [profile] [target] [local-port:remote-port]...",
+	Short: "Add a port-forward to a profile",
+	Long: `Add a target and its port mappings to a port-forward profile of the
+current context. Saving a target already in the profile (same namespace)
+replaces its mappings. Profiles are stored in ~/.kcsi/portforward/<context>.
+
+Examples:
+  kcsi pf save morning -n shop svc/web 8080:http :metrics
+  kcsi pf save morning -n data sts/postgres 5432
+  kcsi pf up morning`,
+	Args:              profileArgs(cobra.MinimumNArgs(3), 1),
+	ValidArgsFunction: portForwardSaveCompletion,
+	RunE:              runPortForwardSave,
+}
+
+var portForwardUpCmd = &cobra.Command{
+	Use:   "up [profile]",
+	Short: "Start every port-forward of a profile",
+	Long: `Start every port-forward of a profile concurrently. Each forward is
+supervised: when it exits it is re-resolved and reconnected with backoff.
+A status table shows every forward until Ctrl+C or kcsi pf down.
+
+Examples:
+  kcsi pf up morning`,
+	Args:              profileArgs(cobra.ExactArgs(1), 1),
+	ValidArgsFunction: profileCompletion,
+	RunE:              runPortForwardUp,
+}
+
+var portForwardDownCmd = &cobra.Command{
+	Use:   "down [profile]",
+	Short: "Stop running port-forward profiles",
+	Long: `Stop a profile started with kcsi pf up, or every running profile of the
+current context when no profile is given.
+
+Examples:
+  kcsi pf down morning
+  kcsi pf down`,
+	Args:              profileArgs(cobra.MaximumNArgs(1), 1),
+	ValidArgsFunction: profileCompletion,
+	RunE:              runPortForwardDown,
+}
+
+var portForwardListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the port-forward profiles of the current context",
+	Args:    profileArgs(cobra.NoArgs, 0),
+	RunE:    runPortForwardList,
+}
+
+func init() {
+	portForwardCmd.AddCommand(portForwardSaveCmd)
+	portForwardCmd.AddCommand(portForwardUpCmd)
+	portForwardCmd.AddCommand(portForwardDownCmd)
+	portForwardCmd.AddCommand(portForwardListCmd)
+
+	portForwardSaveCmd.Flags().StringVarP(&portForwardNamespace, "namespace", "n", "", FlagDescNamespace)
+	portForwardSaveCmd.Flags().String("address", "", "Addresses to listen on (comma separated, default localhost)")
+	portForwardSaveCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
+}
+
+// profileArgs checks the arguments of a profile subcommand and validates
+// the profile names among them, the first names arguments, before they are
+// used in file paths. The subcommands take over the first argument of kcsi
+// pf, so a failed check points a pod named like one at pod/<name>.
+func profileArgs(check cobra.PositionalArgs, names int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		err := check(cmd, args)
+		for i := 0; err == nil && i < names && i < len(args); i++ {
+			err = portforward.ValidateProfileName(args[i])
+		}
+		if err != nil {
+			forward := strings.TrimSpace("kcsi pf pod/" + cmd.CalledAs() + " " + strings.Join(args, " "))
+			return fmt.Errorf("%v\nTo forward a pod named %s, use: %s", err, cmd.CalledAs(), forward)
+		}
+		return nil
+	}
+}
+
+// profileLocation returns the kcsi directory and the current context name
+func profileLocation() (string, string, error) {
+	kcsiDir, err := kcsicontext.GetKcsiDir()
+	if err != nil {
+		return "", "", err
+	}
+	contextName, _ := kcsicontext.GetCurrentContextName()
+	return kcsiDir, contextName, nil
+}
+
+func profileCompletion(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	kcsiDir, contextName, err := profileLocation()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names, _ := portforward.ListProfiles(kcsiDir, contextName)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func portForwardSaveCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return profileCompletion(cmd, args, toComplete)
+	}
+	return portForwardCompletion(cmd, args[1:], toComplete)
+}
+
+func runPortForwardSave(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	address, _ := cmd.Flags().GetString("address")
+	forward := portforward.ProfileForward{
+		Namespace: portForwardScope(),
+		Target:    args[1],
+		Mappings:  args[2:],
+		Address:   address,
+	}
+	if err := forward.Validate(); err != nil {
+		return err
+	}
+
+	kcsiDir, contextName, err := profileLocation()
+	if err != nil {
+		return err
+	}
+	path := portforward.ProfilePath(kcsiDir, contextName, name)
+	profile, err := portforward.LoadProfile(path)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		profile = &portforward.Profile{Name: name, Context: contextName}
+	}
+
+	replaced := profile.Put(forward)
+	if err := profile.Save(path); err != nil {
+		return err
+	}
+
+	action := "Added"
+	if replaced {
+		action = "Updated"
+	}
+	fmt.Printf("✓ %s %s (%s) in profile %s\n", action, forward.Label(), strings.Join(forward.Mappings, " "), name)
+	fmt.Printf("  %d forward(s) in %s\n", len(profile.Forwards), path)
+	return nil
+}
+
+// profileForward is a running forward of a profile and its latest status
+type profileForward struct {
+	spec      portforward.ProfileForward
+	target    portforward.Target
+	mappings  []portforward.Mapping
+	addresses []string
+	locals    []int
+
+	status     string
+	pod        string
+	bindings   []portBinding
+	reconnects int
+}
+
+// profileBoard renders the status of the forwards of a profile as they change
+type profileBoard struct {
+	mu       sync.Mutex
+	profile  string
+	forwards []*profileForward
+	log      []string
+}
+
+// profileLogLines is how many recent events are shown under the status table
+const profileLogLines = 8
+
+func runPortForwardUp(_ *cobra.Command, args []string) error {
+	name := args[0]
+	kcsiDir, contextName, err := profileLocation()
+	if err != nil {
+		return err
+	}
+	profile, err := portforward.LoadProfile(portforward.ProfilePath(kcsiDir, contextName, name))
+	if err != nil {
+		return err
+	}
+	if profile == nil || len(profile.Forwards) == 0 {
+		return fmt.Errorf("profile '%s' not found or empty\nCreate it with: kcsi pf save %s -n <namespace> <target> <local-port:remote-port>", name, name)
+	}
+
+	pidPath := portforward.PIDPath(kcsiDir, contextName, name)
+	if pid, running := runningProfilePID(pidPath, name); running {
+		return fmt.Errorf("profile '%s' is already running (pid %d)\nStop it with: kcsi pf down %s", name, pid, name)
+	}
+
+	forwards, err := prepareProfileForwards(profile, name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(pidPath, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		return fmt.Errorf("failed to write pid file: %w", err)
+	}
+	defer os.Remove(pidPath)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	board := &profileBoard{profile: name, forwards: forwards}
+	board.render()
+
+	var wg sync.WaitGroup
+	for _, f := range forwards {
+		wg.Add(1)
+		go func(f *profileForward) {
+			defer wg.Done()
+			run := supervisedForward(f.target, f.spec.Namespace, f.mappings, f.locals, f.addresses, func(pod string, bindings []portBinding) {
+				board.resolved(f, pod, bindings)
+			})
+			portforward.Supervise(ctx, run, portforward.DefaultBackoff, func(e portforward.Event) {
+				board.event(f, e)
+			})
+		}(f)
+	}
+	wg.Wait()
+
+	fmt.Printf("\nProfile %s stopped\n", name)
+	return nil
+}
+
+// prepareProfileForwards parses the forwards of a profile and assigns their
+// local ports, making sure no two forwards share a port
+func prepareProfileForwards(profile *portforward.Profile, name string) ([]*profileForward, error) {
+	taken := map[int]bool{}
+	forwards := make([]*profileForward, 0, len(profile.Forwards))
+	for _, spec := range profile.Forwards {
+		if err := spec.Validate(); err != nil {
+			return nil, fmt.Errorf("profile %s: %v", name, err)
+		}
+		target, _ := portforward.ParseTarget(spec.Target)
+		mappings, _ := portforward.ParseMappings(spec.Mappings)
+		address := spec.Address
+		if address == "" {
+			address = "localhost"
+		}
+		addresses, _ := portforward.ParseAddresses(address)
+
+		locals, err := assignLocalPorts(mappings, addresses, taken, "kcsi pf up "+name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", spec.Label(), err)
+		}
+		forwards = append(forwards, &profileForward{
+			spec:      spec,
+			target:    target,
+			mappings:  mappings,
+			addresses: addresses,
+			locals:    locals,
+			status:    "connecting",
+		})
+	}
+	return forwards, nil
+}
+
+// resolved records the pod and ports a forward is about to connect to
+func (b *profileBoard) resolved(f *profileForward, pod string, bindings []portBinding) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f.pod = pod
+	f.bindings = bindings
+}
+
+// event updates the status of a forward and renders the board
+func (b *profileBoard) event(f *profileForward, e portforward.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch e.Type {
+	case portforward.EventConnected:
+		f.status = "up"
+	case portforward.EventExited:
+		f.status = fmt.Sprintf("retrying (attempt %d)", e.Attempt)
+		f.reconnects++
+	}
+	b.addLog(formatPortForwardEvent(f.spec.Namespace+"/"+f.target.String(), e, !stdoutIsTerminal()))
+	b.renderLocked()
+}
+
+// addLog keeps the recent events and prints them directly when the board
+// is not redrawn
+func (b *profileBoard) addLog(line string) {
+	b.log = append(b.log, line)
+	if len(b.log) > profileLogLines {
+		b.log = b.log[len(b.log)-profileLogLines:]
+	}
+	if !stdoutIsTerminal() {
+		fmt.Println(line)
+	}
+}
+
+func (b *profileBoard) render() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.renderLocked()
+}
+
+// renderLocked redraws the status table on a terminal. Otherwise the table
+// is printed once and events are logged as lines.
+func (b *profileBoard) renderLocked() {
+	terminal := stdoutIsTerminal()
+	if !terminal && len(b.log) > 0 {
+		return
+	}
+	if terminal {
+		fmt.Print(clearScreen)
+	}
+
+	fmt.Println(colorize(colorGray, fmt.Sprintf("Profile %s - %s - Ctrl+C or kcsi pf down %s to stop", b.profile, time.Now().Format("15:04:05"), b.profile)))
+	table := newColoredTable("NAMESPACE", "TARGET", "LOCAL", "POD", "REMOTE", "STATUS", "RECONNECTS")
+	for _, f := range b.forwards {
+		color := colorGray
+		switch {
+		case f.status == "up":
+			color = colorGreen
+		case strings.HasPrefix(f.status, "retrying"):
+			color = colorYellow
+		}
+
+		pod := f.pod
+		if pod == "" {
+			pod = "-"
+		}
+		var locals, remotes []string
+		for i, local := range f.locals {
+			binding := portBinding{Local: local, Requested: f.mappings[i].Remote}
+			remote := f.mappings[i].Remote
+			if i < len(f.bindings) {
+				binding = f.bindings[i]
+				remote = bindingRemote(binding)
+			}
+			locals = append(locals, bindingLocal(f.addresses, binding))
+			remotes = append(remotes, remote)
+		}
+		table.addRow(color, f.spec.Namespace, f.target.String(), strings.Join(locals, ", "), pod,
+			strings.Join(remotes, ", "), f.status, strconv.Itoa(f.reconnects))
+	}
+	table.print()
+
+	if terminal && len(b.log) > 0 {
+		fmt.Println()
+		for _, line := range b.log {
+			fmt.Println(colorize(colorGray, line))
+		}
+	}
+	if !terminal {
+		fmt.Println()
+	}
+}
+
+// runningProfilePID reads a profile pid file and reports whether that
+// process is still alive and still runs the profile. Stale pid files, left
+// by a crashed run and possibly naming a reused pid, are removed.
+func runningProfilePID(pidPath, name string) (int, bool) {
+	data, err := os.ReadFile(pidPath)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err == nil && processAlive(pid) && isProfileProcess(pid, name) {
+		return pid, true
+	}
+	os.Remove(pidPath)
+	return 0, false
+}
+
+// isProfileProcess reports whether a process is this kcsi binary running
+// the profile, from its command line (its image name on Windows)
+func isProfileProcess(pid int, name string) bool {
+	executable, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	binary := strings.TrimSuffix(filepath.Base(executable), ".exe")
+
+	if runtime.GOOS == "windows" {
+		output, err := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+		return err == nil && strings.Contains(strings.ToLower(string(output)), strings.ToLower(`"`+binary+`.exe"`))
+	}
+
+	output, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "args=").Output()
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 || filepath.Base(fields[0]) != binary {
+		return false
+	}
+	hasUp, hasName := false, false
+	for _, field := range fields[1:] {
+		hasUp = hasUp || field == "up"
+		hasName = hasName || field == name
+	}
+	return hasUp && hasName
+}
+
+// processAlive reports whether a process exists, by sending it signal 0
+// where signals are supported
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
+
+func runPortForwardDown(_ *cobra.Command, args []string) error {
+	kcsiDir, contextName, err := profileLocation()
+	if err != nil {
+		return err
+	}
+
+	names := args
+	if len(names) == 0 {
+		pidFiles, _ := filepath.Glob(filepath.Join(portforward.ProfileDir(kcsiDir, contextName), "*.pid"))
+		for _, pidFile := range pidFiles {
+			names = append(names, strings.TrimSuffix(filepath.Base(pidFile), ".pid"))
+		}
+		if len(names) == 0 {
+			fmt.Println("No port-forward profile is running")
+			return nil
+		}
+	}
+
+	for _, name := range names {
+		pidPath := portforward.PIDPath(kcsiDir, contextName, name)
+		pid, running := runningProfilePID(pidPath, name)
+		if !running {
+			fmt.Printf("Profile %s is not running\n", name)
+			continue
+		}
+
+		process, err := os.FindProcess(pid)
+		if err == nil {
+			// SIGTERM is not deliverable on Windows; the pid was verified above,
+			// so killing it cannot hit an unrelated process
+			if err = process.Signal(syscall.SIGTERM); err != nil {
+				err = process.Kill()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stop profile %s (pid %d): %v", name, pid, err)
+		}
+		fmt.Printf("✓ Stopped profile %s (pid %d)\n", name, pid)
+	}
+	return nil
+}
+
+func runPortForwardList(_ *cobra.Command, _ []string) error {
+	kcsiDir, contextName, err := profileLocation()
+	if err != nil {
+		return err
+	}
+	names, err := portforward.ListProfiles(kcsiDir, contextName)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("No port-forward profiles for this context")
+		fmt.Println("Create one with: kcsi pf save <profile> -n <namespace> <target> <local-port:remote-port>")
+		return nil
+	}
+
+	table := newColoredTable("PROFILE", "NAMESPACE", "TARGET", "MAPPINGS", "STATUS")
+	for _, name := range names {
+		profile, err := portforward.LoadProfile(portforward.ProfilePath(kcsiDir, contextName, name))
+		if err != nil {
+			return err
+		}
+		status, color := "stopped", ""
+		if pid, running := runningProfilePID(portforward.PIDPath(kcsiDir, contextName, name), name); running {
+			status, color = fmt.Sprintf("running (pid %d)", pid), colorGreen
+		}
+		for _, f := range profile.Forwards {
+			table.addRow(color, name, f.Namespace, f.Target, strings.Join(f.Mappings, " "), status)
+		}
+	}
+	table.print()
+	return nil
+}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/capacity"
+	"github.com/stanzinofree/kcsi/pkg/completion"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"gopkg.in/yaml.v3"
+)
+
+var recommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Suggest configuration changes from observed behaviour",
+	Long:  "Suggest configuration changes from observed cluster behaviour",
+}
+
+var recommendResourcesCmd = &cobra.Command{
+	Use:   "resources",
+	Short: "Suggest container requests from sampled usage",
+	Long: `Sample the usage of every container of the deployments and statefulsets in a
+namespace and suggest new requests:
+
+  CPU request     95th percentile of the samples plus headroom
+  memory request  maximum of the samples plus headroom
+
+Existing limits are kept, or raised when they would fall below the new
+request. Limits are never added or lowered.
+
+A patch file is written per workload whose resources change. Nothing is
+applied to the cluster. The longer the sampling window, the better the
+suggestion: sample through a representative load period.
+
+Examples:
+  kcsi recommend resources -n production
+  kcsi recommend resources -n production --duration 30m --interval 30s --headroom 30%
+  kcsi recommend resources -n production --format kustomize --output-dir overlays/prod/resources`,
+	Args: cobra.NoArgs,
+	RunE: runRecommendResources,
+}
+
+func init() {
+	rootCmd.AddCommand(recommendCmd)
+	recommendCmd.AddCommand(recommendResourcesCmd)
+
+	recommendResourcesCmd.Flags().StringP("namespace", "n", "", FlagDescNamespace)
+	recommendResourcesCmd.Flags().Duration("duration", time.Minute, "How long to sample usage")
+	recommendResourcesCmd.Flags().Duration("interval", 10*time.Second, "Time between samples")
+	recommendResourcesCmd.Flags().String("headroom", "20%", "Headroom added on top of the observed usage")
+	recommendResourcesCmd.Flags().String("format", capacity.PatchFormatStrategic, "Patch format: patch (kubectl patch --patch-file) or kustomize")
+	recommendResourcesCmd.Flags().String("output-dir", "kcsi-recommendations", "Directory for the generated patch files")
+
+	recommendResourcesCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
+	recommendResourcesCmd.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{capacity.PatchFormatStrategic, capacity.PatchFormatKustomize}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// writtenPatch is a patch file generated for a workload
+type writtenPatch struct {
+	Kind string
+	Name string
+	Path string
+}
+
+// recommendTarget is a workload whose containers get recommendations
+type recommendTarget struct {
+	Kind     string
+	Workload kubernetes.Workload
+}
+
+func runRecommendResources(cmd *cobra.Command, _ []string) error {
+	namespace, _ := cmd.Flags().GetString("namespace")
+	namespace = kubernetes.InjectDefaultNamespace(namespace)
+	if namespace == "" {
+		return fmt.Errorf(ErrNamespaceRequired)
+	}
+	duration, _ := cmd.Flags().GetDuration("duration")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	format, _ := cmd.Flags().GetString("format")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	headroomFlag, _ := cmd.Flags().GetString("headroom")
+
+	headroom, err := parsePercent(headroomFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --headroom: %v", err)
+	}
+	if interval <= 0 || duration < 0 {
+		return fmt.Errorf("--interval must be greater than 0 and --duration not negative")
+	}
+	if format != capacity.PatchFormatStrategic && format != capacity.PatchFormatKustomize {
+		return fmt.Errorf("unknown format '%s' (valid: %s, %s)", format, capacity.PatchFormatStrategic, capacity.PatchFormatKustomize)
+	}
+
+	var targets []recommendTarget
+	for _, kind := range []string{"Deployment", "StatefulSet"} {
+		workloads, err := kubernetes.ListWorkloads(strings.ToLower(kind)+"s", namespace)
+		if err != nil {
+			return fmt.Errorf("failed to list %ss: %v", strings.ToLower(kind), err)
+		}
+		for _, w := range workloads {
+			targets = append(targets, recommendTarget{Kind: kind, Workload: w})
+		}
+	}
+	if len(targets) == 0 {
+		fmt.Printf("No deployments or statefulsets found in namespace %s\n", namespace)
+		return nil
+	}
+
+	recorder, podLabels, err := sampleContainerUsage(namespace, duration, interval)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Suggested requests for namespace %s (headroom %.0f%%):\n\n", namespace, headroom)
+	table := newColoredTable("WORKLOAD", "CONTAINER", "SAMPLES", "CPU P95", "CPU REQUEST", "MEM MAX", "MEM REQUEST", "LIMITS")
+	var written []writtenPatch
+	for _, target := range targets {
+		w := target.Workload
+		series := recorder.ContainerSeries(func(u capacity.Usage) bool {
+			return w.Spec.Selector.Matches(podLabels[u.Pod])
+		})
+
+		var recs []capacity.Recommendation
+		for _, container := range w.Spec.Template.Spec.Containers {
+			workload := strings.ToLower(target.Kind) + "/" + w.Metadata.Name
+			s, ok := series[container.Name]
+			if !ok {
+				table.addRow(colorGray, workload, container.Name, "0", "-", "no samples", "-", "-", "-")
+				continue
+			}
+			rec := capacity.Recommend(container, *s, headroom/100)
+			recs = append(recs, rec)
+
+			color := ""
+			if rec.Changed() {
+				color = colorYellow
+			}
+			table.addRow(color, workload, container.Name,
+				fmt.Sprintf("%d", rec.Samples),
+				kubernetes.FormatCPU(rec.CPU.P95),
+				quantityChange(rec.Current.Requests["cpu"], rec.Suggested.Requests["cpu"]),
+				kubernetes.FormatMemory(rec.Memory.Max),
+				quantityChange(rec.Current.Requests["memory"], rec.Suggested.Requests["memory"]),
+				limitChanges(rec),
+			)
+		}
+
+		if !anyChanged(recs) {
+			continue
+		}
+		path, err := writeRecommendationPatch(outputDir, namespace, target, recs, format)
+		if err != nil {
+			return err
+		}
+		written = append(written, writtenPatch{Kind: strings.ToLower(target.Kind), Name: w.Metadata.Name, Path: path})
+	}
+	table.print()
+	fmt.Println()
+
+	if len(written) == 0 {
+		fmt.Println("✓ Current requests already match the observed usage, no patches written")
+		return nil
+	}
+
+	if format == capacity.PatchFormatKustomize {
+		snippet, err := writeKustomizeSnippet(outputDir, namespace, written)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Kustomize patches written to %s\n", filepath.Join(outputDir, namespace))
+		fmt.Printf("  Add the patches listed in %s to your kustomization.yaml\n", snippet)
+	} else {
+		fmt.Println("✓ Patch files written:")
+		for _, patch := range written {
+			fmt.Printf("  kubectl patch %s %s -n %s --patch-file %s\n", patch.Kind, patch.Name, namespace, patch.Path)
+		}
+	}
+	fmt.Println("Nothing was applied to the cluster.")
+	return nil
+}
+
+// sampleContainerUsage records per-container usage of a namespace every
+// interval over the duration, stopping early on Ctrl+C, and returns the labels of every pod seen
+func sampleContainerUsage(namespace string, duration, interval time.Duration) (*capacity.Recorder, map[string]map[string]string, error) {
+	podLabels := map[string]map[string]string{}
+	lastLine := ""
+	recorder, err := sampleUsage(interval, duration, func(round int) ([]capacity.Usage, error) {
+		lastLine = renderProgressLine(fmt.Sprintf("Sampling usage %d/%d (every %s, Ctrl+C to stop early)...", round, sampleRounds(interval, duration), interval), lastLine)
+		rows, err := sampleRound(namespace, podLabels)
+		if err != nil {
+			// Keep the warning off the progress line
+			finishProgressLine()
+			lastLine = ""
+		}
+		return rows, err
+	}, func(int, time.Time, []capacity.Usage) {})
+	finishProgressLine()
+	fmt.Println()
+	if err != nil {
+		return nil, nil, err
+	}
+	return recorder, podLabels, nil
+}
+
+// sampleRound returns the container usage of one sampling round and records
+// the labels of the pods seen
+func sampleRound(namespace string, podLabels map[string]map[string]string) ([]capacity.Usage, error) {
+	metrics, err := kubernetes.ListPodMetrics(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %v", err)
+	}
+	pods, err := kubernetes.ListPods(namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	for _, pod := range pods {
+		podLabels[pod.Metadata.Name] = pod.Metadata.Labels
+	}
+	return capacity.MergeUsage(metrics, pods, true), nil
+}
+
+// quantityChange renders "500m → 120m", or the value alone when unchanged
+func quantityChange(current, suggested string) string {
+	if current == "" {
+		current = "none"
+	}
+	a, errA := kubernetes.ParseQuantity(current)
+	b, errB := kubernetes.ParseQuantity(suggested)
+	if errA == nil && errB == nil && a == b {
+		return current
+	}
+	return current + " → " + suggested
+}
+
+// limitChanges lists the limits raised by a recommendation
+func limitChanges(rec capacity.Recommendation) string {
+	var changes []string
+	for _, resource := range []string{"cpu", "memory"} {
+		current, suggested := rec.Current.Limits[resource], rec.Suggested.Limits[resource]
+		if suggested != "" && suggested != current {
+			changes = append(changes, fmt.Sprintf("%s %s → %s", resource, current, suggested))
+		}
+	}
+	if len(changes) == 0 {
+		return "-"
+	}
+	return strings.Join(changes, ", ")
+}
+
+func anyChanged(recs []capacity.Recommendation) bool {
+	for _, rec := range recs {
+		if rec.Changed() {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRecommendationPatch writes <dir>/<namespace>/<kind>-<name>.yaml
+func writeRecommendationPatch(dir, namespace string, target recommendTarget, recs []capacity.Recommendation, format string) (string, error) {
+	patch, err := capacity.RenderPatch(target.Kind, target.Workload.Metadata.Name, namespace, recs, format)
+	if err != nil {
+		return "", err
+	}
+
+	nsDir := filepath.Join(dir, namespace)
+	if err := os.MkdirAll(nsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	path := filepath.Join(nsDir, strings.ToLower(target.Kind)+"-"+target.Workload.Metadata.Name+".yaml")
+	if err := os.WriteFile(path, patch, 0644); err != nil {
+		return "", fmt.Errorf("failed to write patch: %w", err)
+	}
+	return path, nil
+}
+
+// writeKustomizeSnippet writes the patches entries to add to a kustomization.yaml
+func writeKustomizeSnippet(dir, namespace string, patches []writtenPatch) (string, error) {
+	type patchEntry struct {
+		Path string `yaml:"path"`
+	}
+	var snippet struct {
+		Patches []patchEntry `yaml:"patches"`
+	}
+	for _, patch := range patches {
+		snippet.Patches = append(snippet.Patches, patchEntry{Path: filepath.Base(patch.Path)})
+	}
+	sort.Slice(snippet.Patches, func(i, j int) bool {
+		return snippet.Patches[i].Path < snippet.Patches[j].Path
+	})
+
+	data, err := yaml.Marshal(&snippet)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal kustomize snippet: %w", err)
+	}
+	path := filepath.Join(dir, namespace, "kustomization-patches.yaml")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write kustomize snippet: %w", err)
+	}
+	return path, nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
@@ -10,13 +11,22 @@ import (
 var rolloutCmd = &cobra.Command{
 	Use:   "rollout",
 	Short: "Manage rollout of resources",
-	Long:  "Manage the rollout of Kubernetes resources (restart, status, history, undo)",
+	Long:  "Manage the rollout of Kubernetes resources (restart, status, history, diff, undo, pause, resume)",
 }
 
 var rolloutRestartCmd = &cobra.Command{
-	Use:               "restart [resource-type] [name]",
-	Short:             "Restart a resource",
-	Long:              "Restart a deployment, daemonset, or statefulset by triggering a rollout",
+	Use:   "restart [resource-type] [name]",
+	Short: "Restart a resource",
+	Long: `Restart a deployment, daemonset, or statefulset by triggering a rollout.
+
+With --wait, kcsi watches the rollout and shows live progress (updated, ready
+and available replicas) along with the events of failing pods. With --auto-undo,
+the workload is rolled back to the previous revision if the rollout does not
+complete within --timeout.
+
+Examples:
+  kcsi rollout restart deployment web -n production --wait --timeout 5m
+  kcsi rollout restart deployment web -n production --wait --auto-undo`,
 	Args:              cobra.ExactArgs(2),
 	ValidArgsFunction: resourceNameCompletion,
 	RunE:              runRolloutRestart,
@@ -25,7 +35,7 @@ var rolloutRestartCmd = &cobra.Command{
 var rolloutStatusCmd = &cobra.Command{
 	Use:               "status [resource-type] [name]",
 	Short:             "Show rollout status",
-	Long:              "Show the status of the rollout for a deployment, daemonset, or statefulset",
+	Long:              "Show live progress of the rollout for a deployment, daemonset, or statefulset until it completes",
 	Args:              cobra.ExactArgs(2),
 	ValidArgsFunction: resourceNameCompletion,
 	RunE:              runRolloutStatus,
@@ -34,7 +44,7 @@ var rolloutStatusCmd = &cobra.Command{
 var rolloutHistoryCmd = &cobra.Command{
 	Use:               "history [resource-type] [name]",
 	Short:             "View rollout history",
-	Long:              "View previous rollout revisions and configurations",
+	Long:              "View previous rollout revisions with their images, creation time and change cause",
 	Args:              cobra.ExactArgs(2),
 	ValidArgsFunction: resourceNameCompletion,
 	RunE:              runRolloutHistory,
@@ -49,15 +59,36 @@ var rolloutUndoCmd = &cobra.Command{
 	RunE:              runRolloutUndo,
 }
 
+var rolloutPauseCmd = &cobra.Command{
+	Use:               "pause [resource-type] [name]",
+	Short:             "Pause a rollout",
+	Long:              "Pause the rollout of a deployment. Changes to the pod template are not rolled out until it is resumed.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: resourceNameCompletion,
+	RunE:              runRolloutPause,
+}
+
+var rolloutResumeCmd = &cobra.Command{
+	Use:               "resume [resource-type] [name]",
+	Short:             "Resume a paused rollout",
+	Long:              "Resume the rollout of a paused deployment",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: resourceNameCompletion,
+	RunE:              runRolloutResume,
+}
+
 func init() {
 	rootCmd.AddCommand(rolloutCmd)
 	rolloutCmd.AddCommand(rolloutRestartCmd)
 	rolloutCmd.AddCommand(rolloutStatusCmd)
 	rolloutCmd.AddCommand(rolloutHistoryCmd)
 	rolloutCmd.AddCommand(rolloutUndoCmd)
+	rolloutCmd.AddCommand(rolloutDiffCmd)
+	rolloutCmd.AddCommand(rolloutPauseCmd)
+	rolloutCmd.AddCommand(rolloutResumeCmd)
 
 	// Add namespace flag to all rollout subcommands
-	for _, cmd := range []*cobra.Command{rolloutRestartCmd, rolloutStatusCmd, rolloutHistoryCmd, rolloutUndoCmd} {
+	for _, cmd := range []*cobra.Command{rolloutRestartCmd, rolloutStatusCmd, rolloutHistoryCmd, rolloutUndoCmd, rolloutDiffCmd, rolloutPauseCmd, rolloutResumeCmd} {
 		cmd.Flags().StringP("namespace", "n", "", FlagDescNamespace)
 		cmd.RegisterFlagCompletionFunc("namespace", func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 			namespaces, err := kubernetes.GetNamespaces()
@@ -70,6 +101,13 @@ func init() {
 
 	// Add revision flag to undo command
 	rolloutUndoCmd.Flags().Int("to-revision", 0, "Revision to rollback to (0 means previous revision)")
+
+	// Add rollout watch flags
+	rolloutRestartCmd.Flags().Bool("wait", false, "Wait for the rollout to complete and show live progress")
+	for _, cmd := range []*cobra.Command{rolloutRestartCmd, rolloutStatusCmd} {
+		cmd.Flags().Duration("timeout", 5*time.Minute, "How long to wait for the rollout to complete")
+		cmd.Flags().Bool("auto-undo", false, "Roll back to the previous revision if the rollout does not complete")
+	}
 }
 
 func resourceNameCompletion(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -80,21 +118,19 @@ func resourceNameCompletion(cmd *cobra.Command, args []string, _ string) ([]stri
 
 	if len(args) == 1 {
 		// Second argument: resource name based on type
-		namespace, _ := cmd.Flags().GetString("namespace")
-		if namespace == "" {
+		namespace, err := rolloutNamespace(cmd)
+		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 
-		resourceType := args[0]
 		var resources []string
-		var err error
 
-		switch resourceType {
-		case "deployment", "deployments", "deploy":
+		switch rolloutKind(args[0]) {
+		case "deployment":
 			resources, err = kubernetes.GetDeployments(namespace)
-		case "daemonset", "daemonsets", "ds":
+		case "daemonset":
 			resources, err = kubernetes.GetDaemonSets(namespace)
-		case "statefulset", "statefulsets", "sts":
+		case "statefulset":
 			resources, err = kubernetes.GetStatefulSets(namespace)
 		default:
 			return nil, cobra.ShellCompDirectiveNoFileComp
@@ -109,14 +145,41 @@ func resourceNameCompletion(cmd *cobra.Command, args []string, _ string) ([]stri
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
 
-func runRolloutRestart(cmd *cobra.Command, args []string) error {
+// rolloutNamespace returns the namespace from the -n flag, falling back to the
+// default namespace of the current kcsi context
+func rolloutNamespace(cmd *cobra.Command) (string, error) {
 	namespace, _ := cmd.Flags().GetString("namespace")
+	namespace = kubernetes.InjectDefaultNamespace(namespace)
 	if namespace == "" {
-		return fmt.Errorf(ErrNamespaceRequired)
+		return "", fmt.Errorf(ErrNamespaceRequired)
+	}
+	return namespace, nil
+}
+
+// rolloutKind returns the canonical resource type for a rollout resource alias,
+// or an empty string if kubectl rollout does not support the type
+func rolloutKind(resourceType string) string {
+	switch resourceType {
+	case "deployment", "deployments", "deploy":
+		return "deployment"
+	case "daemonset", "daemonsets", "ds":
+		return "daemonset"
+	case "statefulset", "statefulsets", "sts":
+		return "statefulset"
+	}
+	return ""
+}
+
+func runRolloutRestart(cmd *cobra.Command, args []string) error {
+	namespace, err := rolloutNamespace(cmd)
+	if err != nil {
+		return err
 	}
 
 	resourceType := args[0]
 	resourceName := args[1]
+	wait, _ := cmd.Flags().GetBool("wait")
+	autoUndo, _ := cmd.Flags().GetBool("auto-undo")
 
 	output, err := kubernetes.ExecuteKubectl("rollout", "restart", resourceType, resourceName, "-n", namespace)
 	if err != nil {
@@ -124,63 +187,95 @@ func runRolloutRestart(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println(output)
-	return nil
+
+	// --auto-undo needs to watch the rollout, so it implies --wait
+	if !wait && !autoUndo {
+		return nil
+	}
+	return watchRolloutWithUndo(cmd, resourceType, resourceName, namespace)
 }
 
-func runRolloutStatus(cmd *cobra.Command, args []string) error {
-	namespace, _ := cmd.Flags().GetString("namespace")
-	if namespace == "" {
-		return fmt.Errorf(ErrNamespaceRequired)
+// watchRolloutWithUndo watches a rollout and rolls it back on failure when --auto-undo is set
+func watchRolloutWithUndo(cmd *cobra.Command, resourceType, resourceName, namespace string) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	autoUndo, _ := cmd.Flags().GetBool("auto-undo")
+
+	kind := rolloutKind(resourceType)
+	if kind == "" {
+		return fmt.Errorf("unsupported resource type '%s' (use deployment, daemonset or statefulset)", resourceType)
 	}
 
-	resourceType := args[0]
-	resourceName := args[1]
+	watchErr := watchRollout(kind, resourceName, namespace, timeout)
+	if watchErr == nil || !autoUndo {
+		return watchErr
+	}
+
+	fmt.Printf("❌ %v\n", watchErr)
+	if err := undoRollout(kind, resourceName, namespace); err != nil {
+		return err
+	}
+	return fmt.Errorf("rollout of %s/%s did not converge and was rolled back", kind, resourceName)
+}
 
-	output, err := kubernetes.ExecuteKubectl("rollout", "status", resourceType, resourceName, "-n", namespace)
+func runRolloutStatus(cmd *cobra.Command, args []string) error {
+	namespace, err := rolloutNamespace(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to get rollout status: %v", err)
+		return err
 	}
 
-	fmt.Println(output)
-	return nil
+	return watchRolloutWithUndo(cmd, args[0], args[1], namespace)
 }
 
-func runRolloutHistory(cmd *cobra.Command, args []string) error {
-	namespace, _ := cmd.Flags().GetString("namespace")
-	if namespace == "" {
-		return fmt.Errorf(ErrNamespaceRequired)
+func runRolloutUndo(cmd *cobra.Command, args []string) error {
+	namespace, err := rolloutNamespace(cmd)
+	if err != nil {
+		return err
 	}
 
 	resourceType := args[0]
 	resourceName := args[1]
+	toRevision, _ := cmd.Flags().GetInt("to-revision")
+
+	cmdArgs := []string{"rollout", "undo", resourceType, resourceName, "-n", namespace}
+	if toRevision > 0 {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--to-revision=%d", toRevision))
+	}
 
-	output, err := kubernetes.ExecuteKubectl("rollout", "history", resourceType, resourceName, "-n", namespace)
+	output, err := kubernetes.ExecuteKubectl(cmdArgs...)
 	if err != nil {
-		return fmt.Errorf("failed to get rollout history: %v", err)
+		return fmt.Errorf("failed to undo rollout: %v", err)
 	}
 
 	fmt.Println(output)
 	return nil
 }
 
-func runRolloutUndo(cmd *cobra.Command, args []string) error {
-	namespace, _ := cmd.Flags().GetString("namespace")
-	if namespace == "" {
-		return fmt.Errorf(ErrNamespaceRequired)
+func runRolloutPause(cmd *cobra.Command, args []string) error {
+	return runRolloutPauseResume(cmd, args, "pause")
+}
+
+func runRolloutResume(cmd *cobra.Command, args []string) error {
+	return runRolloutPauseResume(cmd, args, "resume")
+}
+
+// runRolloutPauseResume pauses or resumes a deployment rollout
+func runRolloutPauseResume(cmd *cobra.Command, args []string, action string) error {
+	namespace, err := rolloutNamespace(cmd)
+	if err != nil {
+		return err
 	}
 
 	resourceType := args[0]
 	resourceName := args[1]
-	toRevision, _ := cmd.Flags().GetInt("to-revision")
 
-	cmdArgs := []string{"rollout", "undo", resourceType, resourceName, "-n", namespace}
-	if toRevision > 0 {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--to-revision=%d", toRevision))
+	// Only deployments can be paused
+	if rolloutKind(resourceType) != "deployment" {
+		return fmt.Errorf("only deployments support rollout %s", action)
 	}
 
-	output, err := kubernetes.ExecuteKubectl(cmdArgs...)
+	output, err := kubernetes.ExecuteKubectl("rollout", action, resourceType, resourceName, "-n", namespace)
 	if err != nil {
-		return fmt.Errorf("failed to undo rollout: %v", err)
+		return fmt.Errorf("failed to %s rollout: %v", action, err)
 	}
 
 	fmt.Println(output)
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/diff"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// annotationChangeCause records the command that caused a revision
+const annotationChangeCause = "kubernetes.io/change-cause"
+
+// revisionHashLabels are labels added by controllers to tell revisions apart
+var revisionHashLabels = []string{"pod-template-hash", "controller-revision-hash"}
+
+var rolloutDiffCmd = &cobra.Command{
+	Use:   "diff [resource-type] [name] [revision-a] [revision-b]",
+	Short: "Show the pod template diff between two revisions",
+	Long: `Show the field-level pod template diff between two revisions of a
+deployment, daemonset, or statefulset. Use it to pick the right --to-revision for undo.
+
+Examples:
+  kcsi rollout diff deployment web 3 4 -n production`,
+	Args:              cobra.ExactArgs(4),
+	ValidArgsFunction: rolloutDiffCompletion,
+	RunE:              runRolloutDiff,
+}
+
+// workloadRevision is a single rollout revision of a workload
+type workloadRevision struct {
+	Number      int64
+	Source      string
+	Created     time.Time
+	ChangeCause string
+	Images      []string
+	Current     bool
+
+	// replicaSet or rawTemplate locate the pod template of the revision
+	replicaSet  string
+	rawTemplate []byte
+}
+
+func rolloutDiffCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) < 2 {
+		return resourceNameCompletion(cmd, args, toComplete)
+	}
+	if len(args) > 3 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	namespace, err := rolloutNamespace(cmd)
+	kind := rolloutKind(args[0])
+	if err != nil || kind == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	revisions, err := loadRevisions(kind, args[1], namespace)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	numbers := make([]string, 0, len(revisions))
+	for _, rev := range revisions {
+		numbers = append(numbers, strconv.FormatInt(rev.Number, 10))
+	}
+	return numbers, cobra.ShellCompDirectiveNoFileComp
+}
+
+func runRolloutHistory(cmd *cobra.Command, args []string) error {
+	namespace, err := rolloutNamespace(cmd)
+	if err != nil {
+		return err
+	}
+
+	kind := rolloutKind(args[0])
+	if kind == "" {
+		return fmt.Errorf("unsupported resource type '%s' (use deployment, daemonset or statefulset)", args[0])
+	}
+	name := args[1]
+
+	revisions, err := loadRevisions(kind, name, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get rollout history: %v", err)
+	}
+
+	if len(revisions) == 0 {
+		fmt.Printf("No revisions found for %s/%s\n", kind, name)
+		return nil
+	}
+
+	fmt.Printf("Rollout history of %s/%s in namespace %s\n\n", kind, name, namespace)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tCREATED\tIMAGES\tCHANGE-CAUSE")
+	fmt.Fprintln(w, "--------\t-------\t------\t------------")
+
+	for _, rev := range revisions {
+		number := strconv.FormatInt(rev.Number, 10)
+		if rev.Current {
+			number += " (current)"
+		}
+		changeCause := rev.ChangeCause
+		if changeCause == "" {
+			changeCause = "<none>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", number, rev.Created.Local().Format("2006-01-02 15:04:05"),
+			strings.Join(rev.Images, ", "), changeCause)
+	}
+	w.Flush()
+
+	if len(revisions) > 1 {
+		prev := revisions[len(revisions)-2].Number
+		last := revisions[len(revisions)-1].Number
+		fmt.Println()
+		fmt.Printf("💡 Compare revisions: kcsi rollout diff %s %s %d %d -n %s\n", kind, name, prev, last, namespace)
+		fmt.Printf("   Roll back:          kcsi rollout undo %s %s --to-revision=%d -n %s\n", kind, name, prev, namespace)
+	}
+
+	return nil
+}
+
+func runRolloutDiff(cmd *cobra.Command, args []string) error {
+	namespace, err := rolloutNamespace(cmd)
+	if err != nil {
+		return err
+	}
+
+	kind := rolloutKind(args[0])
+	if kind == "" {
+		return fmt.Errorf("unsupported resource type '%s' (use deployment, daemonset or statefulset)", args[0])
+	}
+	name := args[1]
+
+	revA, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid revision '%s'", args[2])
+	}
+	revB, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid revision '%s'", args[3])
+	}
+
+	revisions, err := loadRevisions(kind, name, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get rollout history: %v", err)
+	}
+
+	templateA, err := findRevisionTemplate(revisions, revA, namespace)
+	if err != nil {
+		return err
+	}
+	templateB, err := findRevisionTemplate(revisions, revB, namespace)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pod template diff of %s/%s: revision %d → %d\n\n", kind, name, revA, revB)
+
+	changes := diff.Objects(templateA, templateB)
+	if len(changes) == 0 {
+		fmt.Println("ℹ️  The pod templates are identical")
+		return nil
+	}
+
+	printFieldChanges(changes)
+	return nil
+}
+
+// loadRevisions returns the revisions of a workload sorted by revision number.
+// Deployments keep revisions in replicasets, statefulsets and daemonsets in
+// controllerrevisions.
+func loadRevisions(kind, name, namespace string) ([]workloadRevision, error) {
+	workload, err := kubernetes.GetWorkload(kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []workloadRevision
+	if kind == "deployment" {
+		revisions, err = loadReplicaSetRevisions(workload, namespace)
+	} else {
+		revisions, err = loadControllerRevisions(kind, workload, namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number < revisions[j].Number
+	})
+	return revisions, nil
+}
+
+func loadReplicaSetRevisions(deployment *kubernetes.Workload, namespace string) ([]workloadRevision, error) {
+	replicaSets, err := kubernetes.ListReplicaSets(namespace, deployment.Spec.Selector.String())
+	if err != nil {
+		return nil, err
+	}
+
+	currentRevision := deployment.Metadata.Annotations[annotationRevision]
+
+	var revisions []workloadRevision
+	for _, rs := range replicaSets {
+		if !isOwnedBy(rs.Metadata, deployment.Metadata) {
+			continue
+		}
+		number, err := strconv.ParseInt(rs.Metadata.Annotations[annotationRevision], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		revisions = append(revisions, workloadRevision{
+			Number:      number,
+			Source:      "replicaset/" + rs.Metadata.Name,
+			Created:     rs.Metadata.CreationTimestamp,
+			ChangeCause: rs.Metadata.Annotations[annotationChangeCause],
+			Images:      containerImages(rs.Spec.Template.Spec),
+			Current:     rs.Metadata.Annotations[annotationRevision] == currentRevision,
+			replicaSet:  rs.Metadata.Name,
+		})
+	}
+	return revisions, nil
+}
+
+func loadControllerRevisions(kind string, workload *kubernetes.Workload, namespace string) ([]workloadRevision, error) {
+	controllerRevisions, err := kubernetes.ListControllerRevisions(namespace, workload.Spec.Selector.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []workloadRevision
+	var latest int64
+	for _, cr := range controllerRevisions {
+		if !isOwnedBy(cr.Metadata, workload.Metadata) {
+			continue
+		}
+
+		var images []string
+		if template, err := diff.LoadObject(cr.Data.Spec.Template); err == nil {
+			images = templateImages(template)
+		}
+
+		revisions = append(revisions, workloadRevision{
+			Number:      cr.Revision,
+			Source:      "controllerrevision/" + cr.Metadata.Name,
+			Created:     cr.Metadata.CreationTimestamp,
+			ChangeCause: cr.Metadata.Annotations[annotationChangeCause],
+			Images:      images,
+			Current:     kind == "statefulset" && cr.Metadata.Name == workload.Status.UpdateRevision,
+			rawTemplate: cr.Data.Spec.Template,
+		})
+		if cr.Revision > latest {
+			latest = cr.Revision
+		}
+	}
+
+	// Daemonsets don't report their revision, the latest one is current
+	if kind == "daemonset" {
+		for i := range revisions {
+			revisions[i].Current = revisions[i].Number == latest
+		}
+	}
+	return revisions, nil
+}
+
+// findRevisionTemplate returns the pod template of a revision, without the
+// labels controllers add to tell revisions apart
+func findRevisionTemplate(revisions []workloadRevision, number int64, namespace string) (map[string]interface{}, error) {
+	for _, rev := range revisions {
+		if rev.Number != number {
+			continue
+		}
+
+		template, err := revisionTemplate(rev, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load revision %d: %v", number, err)
+		}
+		delete(template, "$patch")
+		if metadata, ok := template["metadata"].(map[string]interface{}); ok {
+			if labels, ok := metadata["labels"].(map[string]interface{}); ok {
+				for _, label := range revisionHashLabels {
+					delete(labels, label)
+				}
+			}
+		}
+		return template, nil
+	}
+	return nil, fmt.Errorf("revision %d not found", number)
+}
+
+func revisionTemplate(rev workloadRevision, namespace string) (map[string]interface{}, error) {
+	if rev.replicaSet == "" {
+		return diff.LoadObject(rev.rawTemplate)
+	}
+
+	rs, err := kubernetes.GetObject("replicaset", rev.replicaSet, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return nil, fmt.Errorf("replicaset %s no longer exists", rev.replicaSet)
+	}
+
+	spec, _ := rs["spec"].(map[string]interface{})
+	template, ok := spec["template"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("replicaset %s has no pod template", rev.replicaSet)
+	}
+	return template, nil
+}
+
+// containerImages returns the images of the containers in a pod spec
+func containerImages(spec kubernetes.PodSpec) []string {
+	images := make([]string, 0, len(spec.Containers))
+	for _, c := range spec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// templateImages returns the container images of a generic pod template
+func templateImages(template map[string]interface{}) []string {
+	spec, _ := template["spec"].(map[string]interface{})
+	containers, _ := spec["containers"].([]interface{})
+
+	var images []string
+	for _, c := range containers {
+		if container, ok := c.(map[string]interface{}); ok {
+			if image, ok := container["image"].(string); ok {
+				images = append(images, image)
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// rolloutPollInterval is how often the rollout watcher refreshes its state
+const rolloutPollInterval = 2 * time.Second
+
+// rolloutProgress is a snapshot of the progress of a workload rollout
+type rolloutProgress struct {
+	Desired   int
+	Updated   int
+	Ready     int
+	Available int
+	Total     int
+	Done      bool
+	Failure   string
+}
+
+// podIssue is a container problem that blocks a rollout
+type podIssue struct {
+	Pod       string
+	Container string
+	Reason    string
+	Message   string
+}
+
+// blockingWaitingReasons are container waiting reasons that won't resolve on their own
+var blockingWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// watchRollout polls a workload until its rollout converges, fails or times out,
+// rendering live progress along with the events of failing pods
+func watchRollout(kind, name, namespace string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	start := time.Now()
+	reported := map[string]bool{}
+	lastLine := ""
+	replicaSetShown := false
+
+	fmt.Printf("⏳ Watching rollout of %s/%s in namespace %s (timeout %s)\n", kind, name, namespace, timeout)
+
+	for {
+		workload, err := kubernetes.GetWorkload(kind, name, namespace)
+		if err != nil {
+			return fmt.Errorf("failed to get %s/%s: %v", kind, name, err)
+		}
+
+		progress := computeRolloutProgress(kind, workload)
+		line := formatRolloutProgress(progress, time.Since(start))
+		lastLine = renderProgressLine(line, lastLine)
+
+		// The new replicaset is known once the controller observed the change
+		if !replicaSetShown && workload.Status.ObservedGeneration >= workload.Metadata.Generation {
+			replicaSetShown = true
+			if printNewReplicaSet(kind, workload, namespace) {
+				lastLine = ""
+			}
+		}
+
+		if progress.Done {
+			finishProgressLine()
+			fmt.Printf("✅ Rollout of %s/%s completed\n", kind, name)
+			return nil
+		}
+
+		if progress.Failure != "" {
+			finishProgressLine()
+			return fmt.Errorf("rollout of %s/%s failed: %s", kind, name, progress.Failure)
+		}
+
+		if reportPodIssues(workload, namespace, reported) {
+			lastLine = ""
+		}
+
+		if time.Now().After(deadline) {
+			finishProgressLine()
+			return fmt.Errorf("rollout of %s/%s did not complete within %s", kind, name, timeout)
+		}
+
+		time.Sleep(rolloutPollInterval)
+	}
+}
+
+// computeRolloutProgress derives the rollout progress from the workload status
+func computeRolloutProgress(kind string, w *kubernetes.Workload) rolloutProgress {
+	status := w.Status
+	observed := status.ObservedGeneration >= w.Metadata.Generation
+
+	if kind == "daemonset" {
+		p := rolloutProgress{
+			Desired:   status.DesiredNumberScheduled,
+			Updated:   status.UpdatedNumberScheduled,
+			Ready:     status.NumberReady,
+			Available: status.NumberAvailable,
+			Total:     status.DesiredNumberScheduled,
+		}
+		p.Done = observed && p.Updated == p.Desired && p.Available == p.Desired
+		return p
+	}
+
+	p := rolloutProgress{
+		Desired:   w.DesiredReplicas(),
+		Updated:   status.UpdatedReplicas,
+		Ready:     status.ReadyReplicas,
+		Available: status.AvailableReplicas,
+		Total:     status.Replicas,
+	}
+
+	switch kind {
+	case "statefulset":
+		// Statefulsets report no availability before Kubernetes 1.22
+		if p.Available == 0 {
+			p.Available = p.Ready
+		}
+		revisionDone := status.UpdateRevision == "" || status.UpdateRevision == status.CurrentRevision
+		p.Done = observed && p.Updated == p.Desired && p.Ready == p.Desired && revisionDone
+	default:
+		p.Done = observed && p.Updated == p.Desired && p.Total == p.Desired && p.Available == p.Desired
+		for _, cond := range status.Conditions {
+			if cond.Type == "Progressing" && cond.Status == "False" && cond.Reason == "ProgressDeadlineExceeded" {
+				p.Failure = cond.Message
+			}
+		}
+	}
+
+	return p
+}
+
+// formatRolloutProgress renders a one-line progress summary with a bar
+func formatRolloutProgress(p rolloutProgress, elapsed time.Duration) string {
+	const barWidth = 20
+
+	filled := barWidth
+	if p.Desired > 0 {
+		filled = p.Available * barWidth / p.Desired
+	}
+	if filled > barWidth {
+		filled = barWidth
+	}
+	bar := colorize(colorGreen, strings.Repeat("█", filled)) + colorize(colorGray, strings.Repeat("░", barWidth-filled))
+
+	line := fmt.Sprintf("  %s updated %d/%d  ready %d/%d  available %d/%d",
+		bar, p.Updated, p.Desired, p.Ready, p.Desired, p.Available, p.Desired)
+	if p.Total > p.Desired {
+		line += fmt.Sprintf("  (%d old pending termination)", p.Total-p.Desired)
+	}
+	return line + fmt.Sprintf("  [%s]", elapsed.Round(time.Second))
+}
+
+// renderProgressLine redraws the progress line in place on a terminal, or
+// prints it when it changes otherwise. It returns the line now displayed.
+func renderProgressLine(line, lastLine string) string {
+	if stdoutIsTerminal() {
+		fmt.Printf("\r\033[K%s", line)
+		return line
+	}
+
+	// Strip the elapsed time so that unchanged progress is not repeated
+	if trimElapsed(line) != trimElapsed(lastLine) {
+		fmt.Println(line)
+	}
+	return line
+}
+
+func finishProgressLine() {
+	if stdoutIsTerminal() {
+		fmt.Println()
+	}
+}
+
+func trimElapsed(line string) string {
+	if i := strings.LastIndex(line, "  ["); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// printNewReplicaSet shows the replicaset a deployment is rolling out to.
+// It returns true if anything was printed.
+func printNewReplicaSet(kind string, deployment *kubernetes.Workload, namespace string) bool {
+	if kind != "deployment" {
+		return false
+	}
+
+	revision := deployment.Metadata.Annotations[annotationRevision]
+	replicaSets, err := kubernetes.ListReplicaSets(namespace, deployment.Spec.Selector.String())
+	if err != nil {
+		return false
+	}
+
+	for _, rs := range replicaSets {
+		if isOwnedBy(rs.Metadata, deployment.Metadata) && rs.Metadata.Annotations[annotationRevision] == revision {
+			finishProgressLine()
+			fmt.Printf("   ReplicaSet %s (revision %s)\n", rs.Metadata.Name, revision)
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnedBy reports whether child is controlled by owner
+func isOwnedBy(child, owner kubernetes.ObjectMeta) bool {
+	for _, ref := range child.OwnerReferences {
+		if ref.UID == owner.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// reportPodIssues prints newly seen container problems of the workload's pods
+// along with their last events. It returns true if anything was printed.
+func reportPodIssues(w *kubernetes.Workload, namespace string, reported map[string]bool) bool {
+	pods, err := kubernetes.ListPods(namespace, w.Spec.Selector.String())
+	if err != nil {
+		return false
+	}
+
+	printed := false
+	for _, pod := range pods {
+		for _, issue := range findPodIssues(pod) {
+			key := issue.Pod + "/" + issue.Container + "/" + issue.Reason
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+
+			if !printed {
+				finishProgressLine()
+			}
+			printed = true
+			printPodIssue(issue, namespace)
+		}
+	}
+	return printed
+}
+
+// findPodIssues returns the containers of a pod that are stuck in a failing state
+func findPodIssues(pod kubernetes.Pod) []podIssue {
+	var issues []podIssue
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if waiting := cs.State.Waiting; waiting != nil && blockingWaitingReasons[waiting.Reason] {
+			issues = append(issues, podIssue{
+				Pod:       pod.Metadata.Name,
+				Container: cs.Name,
+				Reason:    waiting.Reason,
+				Message:   waiting.Message,
+			})
+		}
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == "PodScheduled" && cond.Status == "False" && cond.Reason == "Unschedulable" {
+			issues = append(issues, podIssue{
+				Pod:     pod.Metadata.Name,
+				Reason:  cond.Reason,
+				Message: cond.Message,
+			})
+		}
+	}
+
+	return issues
+}
+
+// maxIssueEvents is the number of recent events shown for a failing pod
+const maxIssueEvents = 3
+
+func printPodIssue(issue podIssue, namespace string) {
+	target := issue.Pod
+	if issue.Container != "" {
+		target = fmt.Sprintf("%s (container %s)", issue.Pod, issue.Container)
+	}
+	fmt.Printf("  %s %s: %s\n", colorize(colorRed, "✗"), target, colorize(colorRed, issue.Reason))
+	if issue.Message != "" {
+		fmt.Printf("      %s\n", issue.Message)
+	}
+
+	events, err := kubernetes.GetPodEvents(namespace, issue.Pod)
+	if err != nil || len(events) == 0 {
+		return
+	}
+
+	if len(events) > maxIssueEvents {
+		events = events[len(events)-maxIssueEvents:]
+	}
+	for _, event := range events {
+		fmt.Printf("      %s %s: %s\n", colorize(colorGray, event.Timestamp().Local().Format("15:04:05")), event.Reason, event.Message)
+	}
+}
+
+// undoRollout rolls a workload back to its previous revision
+func undoRollout(kind, name, namespace string) error {
+	fmt.Printf("↩️  Rolling back %s/%s to the previous revision...\n", kind, name)
+
+	output, err := kubernetes.ExecuteKubectl("rollout", "undo", kind, name, "-n", namespace)
+	if err != nil {
+		return fmt.Errorf("failed to undo rollout: %v", err)
+	}
+
+	fmt.Print(output)
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/completion"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+var scaleCmd = &cobra.Command{
+	Use:   "scale [resource-type] [name] --replicas N",
+	Short: "Scale a deployment or statefulset",
+	Long: `Set the number of replicas of a deployment or statefulset.
+Shows the current and desired replicas and warns when a HorizontalPodAutoscaler
+manages the workload, since it will override manual scaling. In a protected
+context the change requires --yes.
+
+Examples:
+  kcsi scale deployment web --replicas 5 -n production
+  kcsi scale sts db --replicas 3 -n production --yes`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: scaleCompletion,
+	RunE:              runScale,
+}
+
+func init() {
+	rootCmd.AddCommand(scaleCmd)
+
+	scaleCmd.Flags().StringP("namespace", "n", "", FlagDescNamespace)
+	scaleCmd.Flags().Int("replicas", -1, "Desired number of replicas")
+	scaleCmd.Flags().BoolP("yes", "y", false, "Confirm the change when the current context is protected")
+	scaleCmd.MarkFlagRequired("replicas")
+
+	scaleCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
+}
+
+func scaleCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		// Daemonsets run one pod per node and can't be scaled
+		return []string{"deployment", "statefulset"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return resourceNameCompletion(cmd, args, toComplete)
+}
+
+func runScale(cmd *cobra.Command, args []string) error {
+	namespace, err := rolloutNamespace(cmd)
+	if err != nil {
+		return err
+	}
+
+	kind := rolloutKind(args[0])
+	if kind != "deployment" && kind != "statefulset" {
+		return fmt.Errorf("unsupported resource type '%s' (use deployment or statefulset)", args[0])
+	}
+	name := args[1]
+
+	replicas, _ := cmd.Flags().GetInt("replicas")
+	if replicas < 0 {
+		return fmt.Errorf("--replicas must be 0 or greater")
+	}
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	workload, err := kubernetes.GetWorkload(kind, name, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get %s/%s: %v", kind, name, err)
+	}
+
+	current := workload.DesiredReplicas()
+	fmt.Printf("%s/%s in namespace %s\n", kind, name, namespace)
+	fmt.Printf("  Current replicas: %d (ready %d)\n", current, workload.Status.ReadyReplicas)
+	fmt.Printf("  Desired replicas: %d\n", replicas)
+	fmt.Println()
+
+	if current == replicas {
+		fmt.Println("ℹ️  Already at the desired number of replicas")
+		return nil
+	}
+
+	warnAboutHPA(kind, name, namespace)
+
+	if err := guardProtectedContext(yes); err != nil {
+		return err
+	}
+
+	output, err := kubernetes.ExecuteKubectl("scale", kind, name, "-n", namespace, fmt.Sprintf("--replicas=%d", replicas))
+	if err != nil {
+		return fmt.Errorf("failed to scale %s/%s: %v", kind, name, err)
+	}
+
+	fmt.Print(output)
+	return nil
+}
+
+// warnAboutHPA prints a warning when a HorizontalPodAutoscaler targets the workload
+func warnAboutHPA(kind, name, namespace string) {
+	hpas, err := kubernetes.ListHPAs(namespace)
+	if err != nil {
+		return
+	}
+
+	for _, hpa := range hpas {
+		target := hpa.Spec.ScaleTargetRef
+		if !strings.EqualFold(target.Kind, kind) || target.Name != name {
+			continue
+		}
+
+		minReplicas := 1
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		fmt.Printf("⚠️  Warning: HorizontalPodAutoscaler '%s' manages this %s (min %d, max %d replicas)\n",
+			hpa.Metadata.Name, kind, minReplicas, hpa.Spec.MaxReplicas)
+		fmt.Println("   The autoscaler will override manual scaling on its next sync.")
+		fmt.Println()
+	}
+}
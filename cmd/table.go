@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// coloredTable renders aligned columns where whole rows can be colored.
+// tabwriter counts ANSI escape codes as text, so colored rows are padded
+// on their plain text before the color is applied.
+type coloredTable struct {
+	headers []string
+	rows    [][]string
+	colors  []string
+}
+
+func newColoredTable(headers ...string) *coloredTable {
+	return &coloredTable{headers: headers}
+}
+
+// addRow appends a row; color is one of the color constants or "" for none
+func (t *coloredTable) addRow(color string, cells ...string) {
+	t.rows = append(t.rows, cells)
+	t.colors = append(t.colors, color)
+}
+
+// print writes the table to stdout with three spaces between columns
+func (t *coloredTable) print() {
+	widths := make([]int, len(t.headers))
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		for i, cell := range row {
+			if i < len(widths) && utf8.RuneCountInString(cell) > widths[i] {
+				widths[i] = utf8.RuneCountInString(cell)
+			}
+		}
+	}
+
+	format := func(cells []string) string {
+		var sb strings.Builder
+		for i, cell := range cells {
+			if i == len(cells)-1 {
+				sb.WriteString(cell)
+				break
+			}
+			sb.WriteString(cell)
+			sb.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+3))
+		}
+		return sb.String()
+	}
+
+	fmt.Println(format(t.headers))
+	for i, row := range t.rows {
+		line := format(row)
+		if t.colors[i] != "" {
+			line = colorize(t.colors[i], line)
+		}
+		fmt.Println(line)
+	}
+}
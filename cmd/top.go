@@ -1,7 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/capacity"
 	"github.com/stanzinofree/kcsi/pkg/kubernetes"
 )
 
@@ -14,7 +20,25 @@ var topCmd = &cobra.Command{
 var topPodsCmd = &cobra.Command{
 	Use:   "pods",
 	Short: "Display resource usage of pods",
-	Long:  "Display CPU and memory usage of pods in a namespace",
+	Long: `Display CPU and memory usage of pods next to their requests and limits.
+
+Usage is shown as a percentage of the request and of the limit. Rows at or
+above --warn of a limit are highlighted in red (CPU throttling or OOM kill
+candidates), rows at or above --warn of a request in yellow. Pods have a
+request or limit only when all their containers set one; use --containers
+for the per-container view.
+
+Examples:
+  kcsi top pods -n production
+  kcsi top pods --sort cpu%limit
+  kcsi top pods -n production --containers --warn 90%
+
+With --watch the table refreshes every --interval until --duration elapses or
+Ctrl+C, then min, avg, p95 and max usage per pod are printed next to the
+current requests. --csv writes every sample to a file.
+
+  kcsi top pods -n production --watch --interval 10s --duration 10m
+  kcsi top pods -n production --watch --csv samples.csv`,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
@@ -45,6 +69,16 @@ func init() {
 
 	// Add namespace flag to pods subcommand
 	topPodsCmd.Flags().StringP("namespace", "n", "", "Namespace for the pods")
+	topPodsCmd.Flags().String("sort", capacity.SortName, "Sort by name, cpu, mem, cpu%req, mem%req, cpu%limit or mem%limit")
+	topPodsCmd.Flags().Bool("containers", false, "Show one row per container")
+	topPodsCmd.Flags().String("warn", "80%", "Highlight usage at or above this percentage of the request or limit (0 to disable)")
+	topPodsCmd.Flags().BoolP("watch", "w", false, "Sample repeatedly and summarize usage at the end")
+	topPodsCmd.Flags().Duration("interval", 10*time.Second, "Time between samples with --watch")
+	topPodsCmd.Flags().Duration("duration", 0, "Stop sampling after this duration with --watch (default until Ctrl+C)")
+	topPodsCmd.Flags().String("csv", "", "Write the samples to a CSV file with --watch")
+	topPodsCmd.RegisterFlagCompletionFunc("sort", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return capacity.UsageSortKeys, cobra.ShellCompDirectiveNoFileComp
+	})
 	topPodsCmd.RegisterFlagCompletionFunc("namespace", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		namespaces, err := kubernetes.GetNamespaces()
 		if err != nil {
@@ -56,16 +90,138 @@ func init() {
 
 func runTopPods(cmd *cobra.Command, _ []string) error {
 	namespace, _ := cmd.Flags().GetString("namespace")
+	sortKey, _ := cmd.Flags().GetString("sort")
+	containers, _ := cmd.Flags().GetBool("containers")
+	warnFlag, _ := cmd.Flags().GetString("warn")
 
-	kubectlArgs := []string{"top", "pods"}
+	warn, err := parsePercent(warnFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --warn: %v", err)
+	}
+	if !isUsageSortKey(sortKey) {
+		return fmt.Errorf("unknown sort key '%s' (valid: %s)", sortKey, strings.Join(capacity.UsageSortKeys, ", "))
+	}
 
-	if namespace != "" {
-		kubectlArgs = append(kubectlArgs, "-n", namespace)
-	} else {
-		kubectlArgs = append(kubectlArgs, flagAllNamespaces)
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		opts := topWatchOptions{namespace: namespace, sortKey: sortKey, containers: containers, warn: warn}
+		opts.interval, _ = cmd.Flags().GetDuration("interval")
+		opts.duration, _ = cmd.Flags().GetDuration("duration")
+		opts.csvPath, _ = cmd.Flags().GetString("csv")
+		return runTopPodsWatch(opts)
+	}
+	for _, flag := range []string{"interval", "duration", "csv"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s requires --watch", flag)
+		}
 	}
 
-	return kubernetes.ExecuteKubectlInteractive(kubectlArgs...)
+	rows, err := collectPodUsage(namespace, containers)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("No pod metrics found")
+		return nil
+	}
+
+	capacity.SortUsage(rows, sortKey)
+	printPodUsage(rows, namespace == "", containers, warn)
+	return nil
+}
+
+// collectPodUsage reads pod metrics and merges them with the pod specs
+func collectPodUsage(namespace string, containers bool) ([]capacity.Usage, error) {
+	metrics, err := kubernetes.ListPodMetrics(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %v", err)
+	}
+	pods, err := kubernetes.ListPods(namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	return capacity.MergeUsage(metrics, pods, containers), nil
+}
+
+// printPodUsage renders usage rows, highlighting those at or above warn
+// percent of a limit (red) or of a request (yellow)
+func printPodUsage(rows []capacity.Usage, showNamespace, containers bool, warn float64) {
+	headers := []string{"POD"}
+	if showNamespace {
+		headers = append([]string{"NAMESPACE"}, headers...)
+	}
+	if containers {
+		headers = append(headers, "CONTAINER")
+	}
+	headers = append(headers, "CPU", "CPU/REQ", "CPU/LIM", "MEMORY", "MEM/REQ", "MEM/LIM")
+
+	table := newColoredTable(headers...)
+	overLimit, overRequest := 0, 0
+	for _, row := range rows {
+		color := ""
+		switch {
+		case warn > 0 && (row.CPULimitPercent() >= warn || row.MemoryLimitPercent() >= warn):
+			color = colorRed
+			overLimit++
+		case warn > 0 && (row.CPURequestPercent() >= warn || row.MemoryRequestPercent() >= warn):
+			color = colorYellow
+			overRequest++
+		}
+
+		var cells []string
+		if showNamespace {
+			cells = append(cells, row.Namespace)
+		}
+		cells = append(cells, row.Pod)
+		if containers {
+			cells = append(cells, row.Container)
+		}
+		cells = append(cells,
+			kubernetes.FormatCPU(row.Usage.CPU),
+			percentOf(row.CPURequestPercent(), row.Requests.CPU, kubernetes.FormatCPU),
+			percentOf(row.CPULimitPercent(), row.Limits.CPU, kubernetes.FormatCPU),
+			kubernetes.FormatMemory(row.Usage.Memory),
+			percentOf(row.MemoryRequestPercent(), row.Requests.Memory, kubernetes.FormatMemory),
+			percentOf(row.MemoryLimitPercent(), row.Limits.Memory, kubernetes.FormatMemory),
+		)
+		table.addRow(color, cells...)
+	}
+	table.print()
+
+	if overLimit > 0 || overRequest > 0 {
+		fmt.Println()
+	}
+	if overLimit > 0 {
+		fmt.Println(colorize(colorRed, fmt.Sprintf("%d at or above %.0f%% of a limit: CPU throttling or OOM kill candidates", overLimit, warn)))
+	}
+	if overRequest > 0 {
+		fmt.Println(colorize(colorYellow, fmt.Sprintf("%d at or above %.0f%% of a request", overRequest, warn)))
+	}
+}
+
+// percentOf renders "45% of 1" or "-" when the request or limit is not set
+func percentOf(percent, total float64, format func(float64) string) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s of %s", formatPercent(percent), format(total))
+}
+
+// parsePercent parses a percentage such as "80%" or "80"
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	if err != nil || percent < 0 {
+		return 0, fmt.Errorf("'%s' is not a percentage", value)
+	}
+	return percent, nil
+}
+
+func isUsageSortKey(key string) bool {
+	for _, k := range capacity.UsageSortKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
 }
 
 func runTopNodes(_ *cobra.Command, args []string) error {
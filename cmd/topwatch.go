@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/stanzinofree/kcsi/pkg/capacity"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// topWatchOptions configures a sampling run of top pods
+type topWatchOptions struct {
+	namespace  string
+	sortKey    string
+	containers bool
+	warn       float64
+	interval   time.Duration
+	duration   time.Duration
+	csvPath    string
+}
+
+// runTopPodsWatch samples pod usage every interval, refreshing the table,
+// until the duration elapses or Ctrl+C, then prints per-pod statistics
+func runTopPodsWatch(opts topWatchOptions) error {
+	if opts.interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+
+	start := time.Now()
+	recorder, err := sampleUsage(opts.interval, opts.duration, func(int) ([]capacity.Usage, error) {
+		return collectPodUsage(opts.namespace, opts.containers)
+	}, func(round int, at time.Time, rows []capacity.Usage) {
+		capacity.SortUsage(rows, opts.sortKey)
+		if stdoutIsTerminal() {
+			fmt.Print(clearScreen)
+		} else if round > 1 {
+			fmt.Println()
+		}
+		fmt.Println(colorize(colorGray, topWatchStatus(round, at.Sub(start), opts)))
+		printPodUsage(rows, opts.namespace == "", opts.containers, opts.warn)
+	})
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+	fmt.Println(colorize(colorBold, fmt.Sprintf("Usage over %s (every %s):", time.Since(start).Round(time.Second), opts.interval)))
+	printUsageSummary(recorder.Summary(), opts.namespace == "", opts.containers)
+
+	if opts.csvPath != "" {
+		if err := writeSamplesCSV(recorder, opts.csvPath); err != nil {
+			return err
+		}
+		fmt.Printf("\n✓ %d samples written to %s\n", recorder.Len(), opts.csvPath)
+	}
+	return nil
+}
+
+// sampleUsage calls sample every interval, recording the rows it returns
+// and passing them to render, until Ctrl+C or, when duration is set, until
+// the round at the end of the duration. A failed round is skipped with a
+// warning, the metrics API may be briefly unavailable; it fails only when
+// no round succeeded.
+func sampleUsage(interval, duration time.Duration, sample func(round int) ([]capacity.Usage, error), render func(round int, at time.Time, rows []capacity.Usage)) (*capacity.Recorder, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	recorder := &capacity.Recorder{}
+	for round := 1; ; round++ {
+		rows, err := sample(round)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Sample %d failed: %v\n", round, err)
+		} else {
+			now := time.Now()
+			recorder.Add(now, rows)
+			render(round, now, rows)
+		}
+
+		if duration > 0 && round >= sampleRounds(interval, duration) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+			continue
+		}
+		break
+	}
+
+	if recorder.Len() == 0 {
+		return nil, fmt.Errorf("no samples were recorded")
+	}
+	return recorder, nil
+}
+
+// sampleRounds is the number of rounds of a sampling run with a duration,
+// one at the start and one per interval
+func sampleRounds(interval, duration time.Duration) int {
+	return int(duration/interval) + 1
+}
+
+// topWatchStatus is the status line above the live table
+func topWatchStatus(round int, elapsed time.Duration, opts topWatchOptions) string {
+	status := fmt.Sprintf("Sample %d, every %s, %s elapsed", round, opts.interval, elapsed.Round(time.Second))
+	if opts.duration > 0 {
+		status += fmt.Sprintf(" of %s", opts.duration)
+	}
+	return status + ", Ctrl+C to stop and summarize"
+}
+
+// printUsageSummary renders min/avg/p95/max per pod or container next to
+// the current requests, which is what right-sizing compares against
+func printUsageSummary(summaries []capacity.SeriesSummary, showNamespace, containers bool) {
+	headers := []string{"POD"}
+	if showNamespace {
+		headers = append([]string{"NAMESPACE"}, headers...)
+	}
+	if containers {
+		headers = append(headers, "CONTAINER")
+	}
+	headers = append(headers, "SAMPLES", "CPU MIN/AVG/P95/MAX", "CPU REQ", "MEM MIN/AVG/P95/MAX", "MEM REQ")
+
+	table := newColoredTable(headers...)
+	for _, s := range summaries {
+		var cells []string
+		if showNamespace {
+			cells = append(cells, s.Last.Namespace)
+		}
+		cells = append(cells, s.Last.Pod)
+		if containers {
+			cells = append(cells, s.Last.Container)
+		}
+		cells = append(cells,
+			fmt.Sprintf("%d", s.Samples),
+			formatStats(s.CPU, kubernetes.FormatCPU),
+			formatRequest(s.Last.Requests.CPU, kubernetes.FormatCPU),
+			formatStats(s.Memory, kubernetes.FormatMemory),
+			formatRequest(s.Last.Requests.Memory, kubernetes.FormatMemory),
+		)
+		table.addRow("", cells...)
+	}
+	table.print()
+}
+
+func formatStats(stats capacity.Stats, format func(float64) string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", format(stats.Min), format(stats.Avg), format(stats.P95), format(stats.Max))
+}
+
+func formatRequest(request float64, format func(float64) string) string {
+	if request <= 0 {
+		return "-"
+	}
+	return format(request)
+}
+
+func writeSamplesCSV(recorder *capacity.Recorder, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	if err := recorder.WriteCSV(file); err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return nil
+}
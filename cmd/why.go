@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stanzinofree/kcsi/pkg/completion"
+	"github.com/stanzinofree/kcsi/pkg/diagnose"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why [pod-name]",
+	Short: "Explain why a pod is crashing or not ready",
+	Long: `Gather everything needed to investigate a failing pod in one summary:
+the last termination reason and exit code of each container, the tail of the
+previous container logs, recent events, probe configuration, memory limits
+(to explain OOMKilled restarts) and image pull errors.
+
+The summary ends with the likely causes and what to check next.
+
+Examples:
+  kcsi why -n production web-7d9f8b6c4-x2k9p
+  kcsi why -n production web-7d9f8b6c4-x2k9p --tail 50`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runWhy,
+	ValidArgsFunction: completion.PodCompletion,
+}
+
+var (
+	whyNamespace string
+	whyTail      int
+	whyEvents    int
+)
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+
+	whyCmd.Flags().StringVarP(&whyNamespace, "namespace", "n", "", FlagDescNamespace)
+	whyCmd.Flags().IntVar(&whyTail, "tail", 20, "Lines of previous container logs to show")
+	whyCmd.Flags().IntVar(&whyEvents, "events", 10, "Number of recent pod events to show")
+	whyCmd.RegisterFlagCompletionFunc("namespace", completion.NamespaceCompletion)
+}
+
+func runWhy(_ *cobra.Command, args []string) error {
+	podName := args[0]
+
+	namespace := kubernetes.ResolveNamespace(whyNamespace)
+
+	pod, err := kubernetes.GetPod(namespace, podName)
+	if err != nil {
+		return fmt.Errorf("failed to get pod %s: %v", podName, err)
+	}
+
+	events, err := kubernetes.GetPodEvents(namespace, podName)
+	if err != nil {
+		fmt.Printf("⚠️  Could not read events: %v\n", err)
+	}
+
+	previousLogs := map[string][]string{}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.LastState.Terminated == nil {
+			continue
+		}
+		output, err := kubernetes.ExecuteKubectl("logs", podName, "-n", namespace, "-c", status.Name, "-p", fmt.Sprintf("--tail=%d", whyTail))
+		if err != nil {
+			continue
+		}
+		if output = strings.TrimRight(output, "\n"); output != "" {
+			previousLogs[status.Name] = strings.Split(output, "\n")
+		}
+	}
+
+	causes := diagnose.Analyze(diagnose.Input{Pod: *pod, Events: events, PreviousLogs: previousLogs})
+
+	printWhyPod(pod, namespace)
+	printWhyContainers(pod)
+	printWhyLogs(pod, previousLogs)
+	printWhyEvents(events)
+	printWhyCauses(pod, causes)
+
+	return nil
+}
+
+func printWhyPod(pod *kubernetes.Pod, namespace string) {
+	fmt.Printf("🔍 %s\n\n", colorize(colorBold, fmt.Sprintf("Pod %s/%s", namespace, pod.Metadata.Name)))
+
+	ready := colorize(colorGreen, "yes")
+	if !pod.IsReady() {
+		ready = colorize(colorRed, "no")
+	}
+	phase := pod.Status.Phase
+	if pod.Status.Reason != "" {
+		phase = fmt.Sprintf("%s (%s)", phase, pod.Status.Reason)
+	}
+
+	fmt.Printf("  Phase:    %s\n", phase)
+	fmt.Printf("  Ready:    %s\n", ready)
+	fmt.Printf("  Restarts: %d\n", pod.RestartCount())
+	if pod.Spec.NodeName != "" {
+		fmt.Printf("  Node:     %s\n", pod.Spec.NodeName)
+	}
+	if pod.Status.StartTime != nil {
+		fmt.Printf("  Started:  %s (%s ago)\n", pod.Status.StartTime.Local().Format("2006-01-02 15:04:05"), time.Since(*pod.Status.StartTime).Round(time.Second))
+	}
+	fmt.Println()
+}
+
+func printWhyContainers(pod *kubernetes.Pod) {
+	statuses := map[string]kubernetes.ContainerStatus{}
+	for _, status := range append(append([]kubernetes.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		statuses[status.Name] = status
+	}
+
+	fmt.Println(colorize(colorBold, "Containers"))
+	containers := append(append([]kubernetes.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for i, container := range containers {
+		label := container.Name
+		if i < len(pod.Spec.InitContainers) {
+			label += " (init)"
+		}
+		status := statuses[container.Name]
+
+		fmt.Printf("  %s  %s\n", colorize(colorCyan, label), colorize(colorGray, container.Image))
+		fmt.Printf("    State:      %s\n", describeContainerState(status.State))
+		if status.LastState.Terminated != nil {
+			fmt.Printf("    Last state: %s\n", describeContainerState(status.LastState))
+		}
+		fmt.Printf("    Restarts:   %d\n", status.RestartCount)
+		fmt.Printf("    Memory:     %s\n", describeResource(container.Resources, "memory"))
+		fmt.Printf("    CPU:        %s\n", describeResource(container.Resources, "cpu"))
+		fmt.Printf("    Liveness:   %s\n", diagnose.DescribeProbe(container.LivenessProbe))
+		fmt.Printf("    Readiness:  %s\n", diagnose.DescribeProbe(container.ReadinessProbe))
+		if container.StartupProbe != nil {
+			fmt.Printf("    Startup:    %s\n", diagnose.DescribeProbe(container.StartupProbe))
+		}
+	}
+	fmt.Println()
+}
+
+// describeContainerState renders a container state on a single line
+func describeContainerState(state kubernetes.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		text := colorize(colorYellow, "Waiting ("+state.Waiting.Reason+")")
+		if state.Waiting.Message != "" {
+			text += " " + state.Waiting.Message
+		}
+		return text
+	case state.Running != nil:
+		return colorize(colorGreen, "Running") + fmt.Sprintf(" since %s", state.Running.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	case state.Terminated != nil:
+		t := state.Terminated
+		color := colorRed
+		if t.ExitCode == 0 {
+			color = colorGreen
+		}
+		text := colorize(color, fmt.Sprintf("Terminated (%s, exit code %d)", t.Reason, t.ExitCode))
+		if !t.StartedAt.IsZero() && !t.FinishedAt.IsZero() {
+			text += fmt.Sprintf(" after %s at %s", t.FinishedAt.Sub(t.StartedAt).Round(time.Second), t.FinishedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+		if t.Message != "" {
+			text += " " + strings.TrimSpace(t.Message)
+		}
+		return text
+	}
+	return "unknown"
+}
+
+// describeResource renders the request and limit of a resource
+func describeResource(resources kubernetes.ResourceRequirements, name string) string {
+	request, limit := resources.Requests[name], resources.Limits[name]
+	if request == "" {
+		request = "-"
+	}
+	if limit == "" {
+		limit = colorize(colorYellow, "none")
+	}
+	return fmt.Sprintf("request %s, limit %s", request, limit)
+}
+
+func printWhyLogs(pod *kubernetes.Pod, previousLogs map[string][]string) {
+	for _, status := range pod.Status.ContainerStatuses {
+		lines, ok := previousLogs[status.Name]
+		if !ok {
+			continue
+		}
+		fmt.Println(colorize(colorBold, fmt.Sprintf("Previous logs of %s (last %d lines)", status.Name, len(lines))))
+		for _, line := range lines {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Println()
+	}
+}
+
+func printWhyEvents(events []kubernetes.Event) {
+	fmt.Println(colorize(colorBold, "Recent events"))
+	if len(events) == 0 {
+		fmt.Println("  No events found (events expire after about an hour)")
+		fmt.Println()
+		return
+	}
+
+	if whyEvents > 0 && len(events) > whyEvents {
+		events = events[len(events)-whyEvents:]
+	}
+	for _, event := range events {
+		eventType := event.Type
+		if eventType == "Warning" {
+			eventType = colorize(colorYellow, eventType)
+		}
+		count := ""
+		if event.Count > 1 {
+			count = fmt.Sprintf(" (x%d)", event.Count)
+		}
+		fmt.Printf("  %s %s %s: %s%s\n",
+			colorize(colorGray, event.Timestamp().Local().Format("15:04:05")),
+			eventType, event.Reason, event.Message, count)
+	}
+	fmt.Println()
+}
+
+func printWhyCauses(pod *kubernetes.Pod, causes []diagnose.Cause) {
+	fmt.Println(colorize(colorBold, "Likely causes"))
+	if len(causes) == 0 {
+		if pod.IsReady() {
+			fmt.Println(colorize(colorGreen, "  ✓ No problems detected, the pod is ready"))
+		} else {
+			fmt.Println("  No known failure pattern matched. Check the events and logs above.")
+		}
+		return
+	}
+
+	for i, cause := range causes {
+		summary := cause.Summary
+		if cause.Container != "" {
+			summary = fmt.Sprintf("[%s] %s", cause.Container, summary)
+		}
+		fmt.Printf("  %d. %s %s\n", i+1, colorize(colorRed, "✗"), summary)
+		fmt.Printf("     💡 %s\n", cause.Hint)
+	}
+}
@@ -0,0 +1,160 @@
+// Package attach picks the interactive shell of a container, remembers it
+// per image and names the transcript files of recorded sessions.
+package attach
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Shells are the shells tried, in order of preference
+var Shells = []string{"bash", "zsh", "ash", "sh"}
+
+// ShellOrder returns the shells to probe, starting with the one known to
+// work for the image
+func ShellOrder(known string) []string {
+	if known == "" {
+		return Shells
+	}
+	order := []string{known}
+	for _, shell := range Shells {
+		if shell != known {
+			order = append(order, shell)
+		}
+	}
+	return order
+}
+
+// missingShellMarkers are the messages of a runtime that could not start
+// the shell binary
+var missingShellMarkers = []string{
+	"executable file not found",
+	"no such file or directory",
+	"not found",
+}
+
+// execFailureMarkers are errors of the exec itself, which no other shell
+// would fix
+var execFailureMarkers = []string{
+	"(notfound)",
+	"container not found",
+	"forbidden",
+	"does not have a host assigned",
+	"cannot exec into a container in a completed pod",
+}
+
+// IsShellMissing reports whether a failed probe means the shell is not in
+// the image, as opposed to the pod or exec itself failing. Exit codes 126
+// and 127 are the shell conventions for "cannot execute" and "not found".
+func IsShellMissing(output string, exitCode int) bool {
+	if exitCode == 126 || exitCode == 127 {
+		return true
+	}
+	lower := strings.ToLower(output)
+	for _, marker := range execFailureMarkers {
+		if strings.Contains(lower, marker) {
+			return false
+		}
+	}
+	for _, marker := range missingShellMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProbeCommand is the non-interactive command checking that a shell runs
+func ProbeCommand(shell string) []string {
+	return []string{shell, "-c", "exit 0"}
+}
+
+// SessionCommand starts the shell, first sizing the remote terminal when
+// the local size is known (a recorded session cannot forward resizes)
+func SessionCommand(shell string, rows, cols int) []string {
+	if rows <= 0 || cols <= 0 {
+		return []string{shell}
+	}
+	return []string{shell, "-c", fmt.Sprintf("stty rows %d cols %d 2>/dev/null; exec %s", rows, cols, shell)}
+}
+
+// cacheFile is the file under ~/.kcsi remembering the shell per image
+const cacheFile = "shells.yaml"
+
+// ShellCache remembers the shell that worked for each image
+type ShellCache struct {
+	Images map[string]string `yaml:"images"`
+}
+
+// CachePath returns the shell cache file under the kcsi directory
+func CachePath(kcsiDir string) string {
+	return filepath.Join(kcsiDir, cacheFile)
+}
+
+// LoadShellCache reads the shell cache; a missing file is an empty cache
+func LoadShellCache(path string) (*ShellCache, error) {
+	cache := &ShellCache{Images: map[string]string{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("failed to read shell cache: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse shell cache %s: %w", path, err)
+	}
+	if cache.Images == nil {
+		cache.Images = map[string]string{}
+	}
+	return cache, nil
+}
+
+// Save writes the shell cache, creating its directory if needed
+func (c *ShellCache) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create kcsi directory: %w", err)
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal shell cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write shell cache: %w", err)
+	}
+	return nil
+}
+
+// sessionsSubdir is the directory under ~/.kcsi holding session transcripts
+const sessionsSubdir = "sessions"
+
+// unsafeFileChars are replaced in the parts of a transcript file name
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SessionPath returns the transcript file of a session started at the
+// given time: <kcsi>/sessions/<20060102-150405>_<context>_<namespace>_<pod>.log
+func SessionPath(kcsiDir, contextName, namespace, pod string, at time.Time) string {
+	if contextName == "" {
+		contextName = "default"
+	}
+	parts := []string{at.Format("20060102-150405"), contextName, namespace, pod}
+	for i, part := range parts {
+		parts[i] = unsafeFileChars.ReplaceAllString(part, "-")
+	}
+	return filepath.Join(kcsiDir, sessionsSubdir, strings.Join(parts, "_")+".log")
+}
+
+// SessionHeader is written at the top of a transcript for auditing
+func SessionHeader(at time.Time, user, contextName, namespace, pod, container, image, shell string) string {
+	if contextName == "" {
+		contextName = "default"
+	}
+	return fmt.Sprintf("# kcsi session %s\n# user: %s\n# context: %s\n# pod: %s/%s container: %s image: %s\n# shell: %s\n\n",
+		at.Format(time.RFC3339), user, contextName, namespace, pod, container, image, shell)
+}
@@ -0,0 +1,74 @@
+package attach
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShellOrder(t *testing.T) {
+	if got := ShellOrder(""); !reflect.DeepEqual(got, Shells) {
+		t.Errorf("Expected the default order, got %v", got)
+	}
+	if got := ShellOrder("sh"); !reflect.DeepEqual(got, []string{"sh", "bash", "zsh", "ash"}) {
+		t.Errorf("Expected the known shell first, got %v", got)
+	}
+}
+
+func TestIsShellMissing(t *testing.T) {
+	tests := []struct {
+		output   string
+		exitCode int
+		expected bool
+	}{
+		{`OCI runtime exec failed: exec failed: unable to start container process: exec: "bash": executable file not found in $PATH: unknown`, 1, true},
+		{"command terminated with exit code 127", 127, true},
+		{`Error from server (NotFound): pods "web-9" not found`, 1, false},
+		{`error: unable to upgrade connection: container not found ("app")`, 1, false},
+		{`Error from server (Forbidden): pods "web-1" is forbidden: User cannot create resource "pods/exec"`, 1, false},
+		{"", 1, false},
+	}
+	for _, tt := range tests {
+		if got := IsShellMissing(tt.output, tt.exitCode); got != tt.expected {
+			t.Errorf("IsShellMissing(%q, %d) = %v; expected %v", tt.output, tt.exitCode, got, tt.expected)
+		}
+	}
+}
+
+func TestSessionCommand(t *testing.T) {
+	if got := SessionCommand("bash", 0, 0); !reflect.DeepEqual(got, []string{"bash"}) {
+		t.Errorf("Expected the plain shell without a size, got %v", got)
+	}
+	got := SessionCommand("sh", 40, 120)
+	if len(got) != 3 || !strings.Contains(got[2], "stty rows 40 cols 120") || !strings.HasSuffix(got[2], "exec sh") {
+		t.Errorf("Unexpected sized session command %v", got)
+	}
+}
+
+func TestShellCache(t *testing.T) {
+	path := CachePath(t.TempDir())
+	cache, err := LoadShellCache(path)
+	if err != nil || len(cache.Images) != 0 {
+		t.Fatalf("Expected an empty cache, got %+v (%v)", cache, err)
+	}
+
+	cache.Images["alpine:3.20"] = "ash"
+	if err := cache.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadShellCache(path)
+	if err != nil || loaded.Images["alpine:3.20"] != "ash" {
+		t.Errorf("Expected ash for alpine:3.20, got %+v (%v)", loaded, err)
+	}
+}
+
+func TestSessionPath(t *testing.T) {
+	at := time.Date(2026, 10, 19, 15, 4, 5, 0, time.UTC)
+	got := SessionPath("/home/u/.kcsi", "", "shop", "web/1", at)
+	expected := filepath.Join("/home/u/.kcsi", "sessions", "20261019-150405_default_shop_web-1.log")
+	if got != expected {
+		t.Errorf("SessionPath = %s; expected %s", got, expected)
+	}
+}
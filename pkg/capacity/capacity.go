@@ -0,0 +1,189 @@
+// Package capacity computes resource requests and limits from pod specs and
+// compares them with node allocatable resources.
+package capacity
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// Resources holds CPU in cores and memory in bytes
+type Resources struct {
+	CPU    float64
+	Memory float64
+}
+
+// Add returns the sum of two resource amounts
+func (r Resources) Add(other Resources) Resources {
+	return Resources{CPU: r.CPU + other.CPU, Memory: r.Memory + other.Memory}
+}
+
+// Max returns the per-resource maximum of two resource amounts
+func (r Resources) Max(other Resources) Resources {
+	if other.CPU > r.CPU {
+		r.CPU = other.CPU
+	}
+	if other.Memory > r.Memory {
+		r.Memory = other.Memory
+	}
+	return r
+}
+
+// fromQuantities parses the cpu and memory entries of a requests or limits
+// map; invalid quantities count as zero
+func fromQuantities(quantities map[string]string) Resources {
+	var r Resources
+	if q, ok := quantities["cpu"]; ok {
+		r.CPU, _ = kubernetes.ParseQuantity(q)
+	}
+	if q, ok := quantities["memory"]; ok {
+		r.Memory, _ = kubernetes.ParseQuantity(q)
+	}
+	return r
+}
+
+// ContainerResources returns the requests and limits of a container. A
+// limit without a request implies an equal request, as in the API server.
+func ContainerResources(container kubernetes.Container) (requests, limits Resources) {
+	requests = fromQuantities(container.Resources.Requests)
+	limits = fromQuantities(container.Resources.Limits)
+	if _, ok := container.Resources.Requests["cpu"]; !ok {
+		requests.CPU = limits.CPU
+	}
+	if _, ok := container.Resources.Requests["memory"]; !ok {
+		requests.Memory = limits.Memory
+	}
+	return requests, limits
+}
+
+// PodResources returns the effective requests and limits of a pod the way
+// the scheduler computes them: the sum over app containers, raised to the
+// largest init container since init containers run one at a time.
+func PodResources(spec kubernetes.PodSpec) (requests, limits Resources) {
+	for _, container := range spec.Containers {
+		req, lim := ContainerResources(container)
+		requests = requests.Add(req)
+		limits = limits.Add(lim)
+	}
+	for _, container := range spec.InitContainers {
+		req, lim := ContainerResources(container)
+		requests = requests.Max(req)
+		limits = limits.Max(lim)
+	}
+	return requests, limits
+}
+
+// Percent returns value as a percentage of total, or 0 when total is unknown
+func Percent(value, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return value / total * 100
+}
+
+// NodeSummary aggregates the pods scheduled on a node
+type NodeSummary struct {
+	Node        kubernetes.Node
+	Allocatable Resources
+	Requests    Resources
+	Limits      Resources
+	Pods        int
+	// MaxPods is the allocatable pod count, 0 when unknown
+	MaxPods int
+}
+
+// CPURequestPercent returns the CPU requests as a percentage of allocatable CPU
+func (s NodeSummary) CPURequestPercent() float64 {
+	return Percent(s.Requests.CPU, s.Allocatable.CPU)
+}
+
+// CPULimitPercent returns the CPU limits as a percentage of allocatable CPU
+func (s NodeSummary) CPULimitPercent() float64 {
+	return Percent(s.Limits.CPU, s.Allocatable.CPU)
+}
+
+// MemoryRequestPercent returns the memory requests as a percentage of allocatable memory
+func (s NodeSummary) MemoryRequestPercent() float64 {
+	return Percent(s.Requests.Memory, s.Allocatable.Memory)
+}
+
+// MemoryLimitPercent returns the memory limits as a percentage of allocatable memory
+func (s NodeSummary) MemoryLimitPercent() float64 {
+	return Percent(s.Limits.Memory, s.Allocatable.Memory)
+}
+
+// PodPercent returns the pod count as a percentage of allocatable pods
+func (s NodeSummary) PodPercent() float64 {
+	return Percent(float64(s.Pods), float64(s.MaxPods))
+}
+
+// SummarizeNodes adds up the requests and limits of the pods running on
+// each node. Pods that finished (Succeeded or Failed) no longer hold
+// resources and are skipped.
+func SummarizeNodes(nodes []kubernetes.Node, pods []kubernetes.Pod) []NodeSummary {
+	summaries := make([]NodeSummary, len(nodes))
+	byName := map[string]*NodeSummary{}
+	for i, node := range nodes {
+		summaries[i] = NodeSummary{
+			Node:        node,
+			Allocatable: fromQuantities(node.Status.Allocatable),
+		}
+		summaries[i].MaxPods, _ = strconv.Atoi(node.Status.Allocatable["pods"])
+		byName[node.Metadata.Name] = &summaries[i]
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+			continue
+		}
+		summary, ok := byName[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		requests, limits := PodResources(pod.Spec)
+		summary.Requests = summary.Requests.Add(requests)
+		summary.Limits = summary.Limits.Add(limits)
+		summary.Pods++
+	}
+
+	return summaries
+}
+
+// Sort keys for node summaries
+const (
+	SortName   = "name"
+	SortCPU    = "cpu"
+	SortMemory = "mem"
+	SortPods   = "pods"
+)
+
+// SortKeys lists the valid sort keys
+var SortKeys = []string{SortName, SortCPU, SortMemory, SortPods}
+
+// SortNodes orders summaries by name, or by descending CPU request,
+// memory request or pod percentage. It reports false for an unknown key.
+func SortNodes(summaries []NodeSummary, key string) bool {
+	var value func(s NodeSummary) float64
+	switch key {
+	case SortName:
+		sort.SliceStable(summaries, func(i, j int) bool {
+			return summaries[i].Node.Metadata.Name < summaries[j].Node.Metadata.Name
+		})
+		return true
+	case SortCPU:
+		value = NodeSummary.CPURequestPercent
+	case SortMemory:
+		value = NodeSummary.MemoryRequestPercent
+	case SortPods:
+		value = NodeSummary.PodPercent
+	default:
+		return false
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return value(summaries[i]) > value(summaries[j])
+	})
+	return true
+}
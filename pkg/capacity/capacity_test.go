@@ -0,0 +1,95 @@
+package capacity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+func TestPodResources(t *testing.T) {
+	var spec kubernetes.PodSpec
+	data := `{
+		"containers": [
+			{"name": "app", "resources": {"requests": {"cpu": "250m", "memory": "128Mi"}, "limits": {"cpu": "1", "memory": "256Mi"}}},
+			{"name": "proxy", "resources": {"limits": {"cpu": "100m", "memory": "64Mi"}}}
+		],
+		"initContainers": [
+			{"name": "migrate", "resources": {"requests": {"cpu": "2", "memory": "64Mi"}}}
+		]
+	}`
+	if err := json.Unmarshal([]byte(data), &spec); err != nil {
+		t.Fatalf("Failed to decode spec: %v", err)
+	}
+
+	requests, limits := PodResources(spec)
+
+	// proxy requests default to its limits; the init container raises CPU
+	if requests.CPU != 2 {
+		t.Errorf("Expected CPU request 2, got %v", requests.CPU)
+	}
+	if requests.Memory != 192*1024*1024 {
+		t.Errorf("Expected memory request 192Mi, got %v", requests.Memory)
+	}
+	if limits.CPU != 1.1 || limits.Memory != 320*1024*1024 {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+}
+
+func TestSummarizeNodes(t *testing.T) {
+	var nodes []kubernetes.Node
+	data := `[
+		{"metadata": {"name": "node-a"}, "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}}},
+		{"metadata": {"name": "node-b"}, "status": {"allocatable": {"cpu": "2", "memory": "4Gi", "pods": "10"}}}
+	]`
+	if err := json.Unmarshal([]byte(data), &nodes); err != nil {
+		t.Fatalf("Failed to decode nodes: %v", err)
+	}
+
+	var pods []kubernetes.Pod
+	data = `[
+		{"spec": {"nodeName": "node-a", "containers": [{"resources": {"requests": {"cpu": "1", "memory": "2Gi"}, "limits": {"cpu": "2"}}}]}, "status": {"phase": "Running"}},
+		{"spec": {"nodeName": "node-a", "containers": [{"resources": {"requests": {"cpu": "1"}}}]}, "status": {"phase": "Succeeded"}},
+		{"spec": {"nodeName": "node-b", "containers": [{"resources": {"requests": {"cpu": "1500m"}}}]}, "status": {"phase": "Running"}},
+		{"spec": {"containers": [{"resources": {"requests": {"cpu": "1"}}}]}, "status": {"phase": "Pending"}}
+	]`
+	if err := json.Unmarshal([]byte(data), &pods); err != nil {
+		t.Fatalf("Failed to decode pods: %v", err)
+	}
+
+	summaries := SummarizeNodes(nodes, pods)
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 summaries, got %d", len(summaries))
+	}
+
+	a := summaries[0]
+	if a.Pods != 1 || a.MaxPods != 110 {
+		t.Errorf("node-a: expected 1/110 pods, got %d/%d", a.Pods, a.MaxPods)
+	}
+	if a.CPURequestPercent() != 25 || a.CPULimitPercent() != 50 || a.MemoryRequestPercent() != 25 {
+		t.Errorf("node-a: unexpected percentages cpu=%v/%v mem=%v", a.CPURequestPercent(), a.CPULimitPercent(), a.MemoryRequestPercent())
+	}
+
+	if summaries[1].CPURequestPercent() != 75 {
+		t.Errorf("node-b: expected 75%% CPU requested, got %v", summaries[1].CPURequestPercent())
+	}
+
+	if !SortNodes(summaries, SortCPU) || summaries[0].Node.Metadata.Name != "node-b" {
+		t.Errorf("Expected node-b first when sorting by cpu")
+	}
+	if !SortNodes(summaries, SortName) || summaries[0].Node.Metadata.Name != "node-a" {
+		t.Errorf("Expected node-a first when sorting by name")
+	}
+	if SortNodes(summaries, "disk") {
+		t.Errorf("Expected unknown sort key to be rejected")
+	}
+}
+
+func TestPercent(t *testing.T) {
+	if Percent(1, 0) != 0 {
+		t.Errorf("Expected 0 for unknown total")
+	}
+	if Percent(1, 4) != 25 {
+		t.Errorf("Expected 25, got %v", Percent(1, 4))
+	}
+}
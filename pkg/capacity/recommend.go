@@ -0,0 +1,190 @@
+package capacity
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+	"gopkg.in/yaml.v3"
+)
+
+// Smallest recommended values and rounding steps
+const (
+	cpuStep    = 0.005           // 5m
+	memoryStep = 1024 * 1024 * 4 // 4Mi
+	minCPU     = 0.01            // 10m
+	minMemory  = 1024 * 1024 * 16
+)
+
+// Series holds the usage values of a container across samples and pods
+type Series struct {
+	CPU    []float64
+	Memory []float64
+}
+
+// ContainerSeries groups the recorded container samples accepted by match
+// by container name. Samples must have been recorded per container.
+func (r *Recorder) ContainerSeries(match func(u Usage) bool) map[string]*Series {
+	series := map[string]*Series{}
+	for _, sample := range r.samples {
+		if sample.Container == "" || !match(sample.Usage) {
+			continue
+		}
+		s, ok := series[sample.Container]
+		if !ok {
+			s = &Series{}
+			series[sample.Container] = s
+		}
+		s.CPU = append(s.CPU, sample.Usage.Usage.CPU)
+		s.Memory = append(s.Memory, sample.Usage.Usage.Memory)
+	}
+	return series
+}
+
+// Recommendation is the suggested resources of a container
+type Recommendation struct {
+	Container string
+	Samples   int
+	CPU       Stats
+	Memory    Stats
+	// Current are the requests and limits in the workload spec
+	Current kubernetes.ResourceRequirements
+	// Suggested are the new requests and limits as quantities
+	Suggested kubernetes.ResourceRequirements
+}
+
+// Changed reports whether the suggestion differs from the current spec
+func (r Recommendation) Changed() bool {
+	for _, pair := range []struct{ current, suggested map[string]string }{
+		{r.Current.Requests, r.Suggested.Requests},
+		{r.Current.Limits, r.Suggested.Limits},
+	} {
+		for resource, quantity := range pair.suggested {
+			current, ok := pair.current[resource]
+			if !ok {
+				return true
+			}
+			a, errA := kubernetes.ParseQuantity(current)
+			b, errB := kubernetes.ParseQuantity(quantity)
+			if errA != nil || errB != nil || a != b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Recommend suggests requests from the observed usage plus headroom (0.2
+// for 20%): the CPU request covers the 95th percentile, since CPU is
+// throttled rather than fatal, and the memory request covers the maximum,
+// since running out of memory kills the container. Existing limits are
+// kept, or raised to the new request when they would fall below it;
+// limits are never added or lowered.
+func Recommend(container kubernetes.Container, series Series, headroom float64) Recommendation {
+	rec := Recommendation{
+		Container: container.Name,
+		Samples:   len(series.CPU),
+		CPU:       ComputeStats(series.CPU),
+		Memory:    ComputeStats(series.Memory),
+		Current:   container.Resources,
+		Suggested: kubernetes.ResourceRequirements{
+			Requests: map[string]string{},
+			Limits:   map[string]string{},
+		},
+	}
+
+	cpu := roundUp(math.Max(rec.CPU.P95*(1+headroom), minCPU), cpuStep)
+	memory := roundUp(math.Max(rec.Memory.Max*(1+headroom), minMemory), memoryStep)
+	rec.Suggested.Requests["cpu"] = CPUQuantity(cpu)
+	rec.Suggested.Requests["memory"] = MemoryQuantity(memory)
+
+	if limit, ok := container.Resources.Limits["cpu"]; ok {
+		rec.Suggested.Limits["cpu"] = limit
+		if value, err := kubernetes.ParseQuantity(limit); err == nil && value < cpu {
+			rec.Suggested.Limits["cpu"] = CPUQuantity(cpu)
+		}
+	}
+	if limit, ok := container.Resources.Limits["memory"]; ok {
+		rec.Suggested.Limits["memory"] = limit
+		if value, err := kubernetes.ParseQuantity(limit); err == nil && value < memory {
+			rec.Suggested.Limits["memory"] = MemoryQuantity(memory)
+		}
+	}
+
+	return rec
+}
+
+func roundUp(value, step float64) float64 {
+	return math.Ceil(value/step-1e-9) * step
+}
+
+// CPUQuantity renders cores as a millicore quantity ("120m")
+func CPUQuantity(cores float64) string {
+	return fmt.Sprintf("%dm", int64(math.Round(cores*1000)))
+}
+
+// MemoryQuantity renders bytes as a mebibyte quantity ("256Mi")
+func MemoryQuantity(bytes float64) string {
+	return fmt.Sprintf("%dMi", int64(math.Ceil(bytes/(1024*1024))))
+}
+
+// Patch formats
+const (
+	PatchFormatStrategic = "patch"
+	PatchFormatKustomize = "kustomize"
+)
+
+type patchContainer struct {
+	Name      string `yaml:"name"`
+	Resources struct {
+		Requests map[string]string `yaml:"requests,omitempty"`
+		Limits   map[string]string `yaml:"limits,omitempty"`
+	} `yaml:"resources"`
+}
+
+type patchDocument struct {
+	APIVersion string `yaml:"apiVersion,omitempty"`
+	Kind       string `yaml:"kind,omitempty"`
+	Metadata   *struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata,omitempty"`
+	Spec struct {
+		Template struct {
+			Spec struct {
+				Containers []patchContainer `yaml:"containers"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+// RenderPatch returns a strategic merge patch setting the suggested
+// resources of a workload. The patch format is for `kubectl patch
+// --patch-file`; the kustomize format adds the apiVersion, kind and
+// metadata kustomize needs to find the target.
+func RenderPatch(kind, name, namespace string, recs []Recommendation, format string) ([]byte, error) {
+	var doc patchDocument
+	switch format {
+	case PatchFormatStrategic:
+	case PatchFormatKustomize:
+		doc.APIVersion = "apps/v1"
+		doc.Kind = kind
+		doc.Metadata = &struct {
+			Name      string `yaml:"name"`
+			Namespace string `yaml:"namespace"`
+		}{Name: name, Namespace: namespace}
+	default:
+		return nil, fmt.Errorf("unknown patch format '%s' (valid: %s, %s)", format, PatchFormatStrategic, PatchFormatKustomize)
+	}
+
+	for _, rec := range recs {
+		container := patchContainer{Name: rec.Container}
+		container.Resources.Requests = rec.Suggested.Requests
+		if len(rec.Suggested.Limits) > 0 {
+			container.Resources.Limits = rec.Suggested.Limits
+		}
+		doc.Spec.Template.Spec.Containers = append(doc.Spec.Template.Spec.Containers, container)
+	}
+
+	return yaml.Marshal(doc)
+}
@@ -0,0 +1,112 @@
+package capacity
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+const mi = 1024 * 1024
+
+func TestRecommend(t *testing.T) {
+	var container kubernetes.Container
+	data := `{"name": "app", "resources": {
+		"requests": {"cpu": "1", "memory": "1Gi"},
+		"limits": {"cpu": "2", "memory": "200Mi"}
+	}}`
+	if err := json.Unmarshal([]byte(data), &container); err != nil {
+		t.Fatalf("Failed to decode container: %v", err)
+	}
+
+	series := Series{
+		CPU:    []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.9},
+		Memory: []float64{200 * mi, 250 * mi},
+	}
+	rec := Recommend(container, series, 0.2)
+
+	// p95 of 20 samples is the 19th value (100m) plus 20%
+	if got := rec.Suggested.Requests["cpu"]; got != "120m" {
+		t.Errorf("Expected cpu request 120m, got %s", got)
+	}
+	if got := rec.Suggested.Requests["memory"]; got != "300Mi" {
+		t.Errorf("Expected memory request 300Mi, got %s", got)
+	}
+	if got := rec.Suggested.Limits["cpu"]; got != "2" {
+		t.Errorf("Expected cpu limit to be kept, got %s", got)
+	}
+	if got := rec.Suggested.Limits["memory"]; got != "300Mi" {
+		t.Errorf("Expected memory limit raised to the request, got %s", got)
+	}
+	if !rec.Changed() {
+		t.Errorf("Expected the recommendation to differ from the spec")
+	}
+}
+
+func TestRecommendMinimumsAndNoLimits(t *testing.T) {
+	rec := Recommend(kubernetes.Container{Name: "sidecar"}, Series{CPU: []float64{0.001}, Memory: []float64{mi}}, 0.2)
+	if rec.Suggested.Requests["cpu"] != "10m" || rec.Suggested.Requests["memory"] != "16Mi" {
+		t.Errorf("Expected minimum requests, got %+v", rec.Suggested.Requests)
+	}
+	if len(rec.Suggested.Limits) != 0 {
+		t.Errorf("Expected no limits to be added, got %+v", rec.Suggested.Limits)
+	}
+
+	unchanged := Recommendation{
+		Current:   kubernetes.ResourceRequirements{Requests: map[string]string{"cpu": "0.01", "memory": "16Mi"}},
+		Suggested: kubernetes.ResourceRequirements{Requests: map[string]string{"cpu": "10m", "memory": "16Mi"}},
+	}
+	if unchanged.Changed() {
+		t.Errorf("Expected equal quantities in different notations to be unchanged")
+	}
+}
+
+func TestContainerSeries(t *testing.T) {
+	var r Recorder
+	at := time.Now()
+	r.Add(at, []Usage{
+		{Namespace: "shop", Pod: "web-1", Container: "app", Usage: Resources{CPU: 0.1}},
+		{Namespace: "shop", Pod: "web-2", Container: "app", Usage: Resources{CPU: 0.2}},
+		{Namespace: "shop", Pod: "db-0", Container: "db", Usage: Resources{CPU: 1}},
+	})
+
+	series := r.ContainerSeries(func(u Usage) bool { return strings.HasPrefix(u.Pod, "web-") })
+	if len(series) != 1 || len(series["app"].CPU) != 2 {
+		t.Errorf("Expected the app samples of both web pods, got %+v", series)
+	}
+}
+
+func TestRenderPatch(t *testing.T) {
+	recs := []Recommendation{{
+		Container: "app",
+		Suggested: kubernetes.ResourceRequirements{
+			Requests: map[string]string{"cpu": "120m", "memory": "300Mi"},
+			Limits:   map[string]string{},
+		},
+	}}
+
+	patch, err := RenderPatch("Deployment", "web", "shop", recs, PatchFormatStrategic)
+	if err != nil {
+		t.Fatalf("RenderPatch failed: %v", err)
+	}
+	if strings.Contains(string(patch), "kind:") || strings.Contains(string(patch), "limits") {
+		t.Errorf("Strategic patch should only contain the spec:\n%s", patch)
+	}
+	if !strings.Contains(string(patch), "- name: app") || !strings.Contains(string(patch), "cpu: 120m") {
+		t.Errorf("Unexpected patch:\n%s", patch)
+	}
+
+	patch, err = RenderPatch("StatefulSet", "db", "shop", recs, PatchFormatKustomize)
+	if err != nil {
+		t.Fatalf("RenderPatch failed: %v", err)
+	}
+	if !strings.HasPrefix(string(patch), "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n    name: db\n") {
+		t.Errorf("Unexpected kustomize patch:\n%s", patch)
+	}
+
+	if _, err := RenderPatch("Deployment", "web", "shop", recs, "json"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
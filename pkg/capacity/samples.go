@@ -0,0 +1,138 @@
+package capacity
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Stats summarizes a series of samples
+type Stats struct {
+	Min float64
+	Avg float64
+	P95 float64
+	Max float64
+}
+
+// ComputeStats returns the minimum, mean, 95th percentile (nearest rank)
+// and maximum of values
+func ComputeStats(values []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+
+	return Stats{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		P95: sorted[rank],
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// Sample is the usage of a pod or container at a point in time
+type Sample struct {
+	Time time.Time
+	Usage
+}
+
+// SeriesSummary holds the statistics of one pod or container over a recording
+type SeriesSummary struct {
+	// Last is the most recent row, with the current requests and limits
+	Last    Usage
+	Samples int
+	CPU     Stats
+	Memory  Stats
+}
+
+// Recorder collects usage samples over time
+type Recorder struct {
+	samples []Sample
+}
+
+// Add records the rows of one sampling round
+func (r *Recorder) Add(at time.Time, rows []Usage) {
+	for _, row := range rows {
+		r.samples = append(r.samples, Sample{Time: at, Usage: row})
+	}
+}
+
+// Len returns the number of recorded samples
+func (r *Recorder) Len() int {
+	return len(r.samples)
+}
+
+// Summary returns the statistics per pod or container, sorted by key
+func (r *Recorder) Summary() []SeriesSummary {
+	type series struct {
+		last        Usage
+		cpu, memory []float64
+	}
+	byKey := map[string]*series{}
+	var keys []string
+	for _, sample := range r.samples {
+		key := sample.Key()
+		s, ok := byKey[key]
+		if !ok {
+			s = &series{}
+			byKey[key] = s
+			keys = append(keys, key)
+		}
+		s.last = sample.Usage
+		s.cpu = append(s.cpu, sample.Usage.Usage.CPU)
+		s.memory = append(s.memory, sample.Usage.Usage.Memory)
+	}
+	sort.Strings(keys)
+
+	summaries := make([]SeriesSummary, 0, len(keys))
+	for _, key := range keys {
+		s := byKey[key]
+		summaries = append(summaries, SeriesSummary{
+			Last:    s.last,
+			Samples: len(s.cpu),
+			CPU:     ComputeStats(s.cpu),
+			Memory:  ComputeStats(s.memory),
+		})
+	}
+	return summaries
+}
+
+// WriteCSV writes every sample with CPU in cores and memory in bytes
+func (r *Recorder) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"timestamp", "namespace", "pod", "container", "cpu_cores", "memory_bytes",
+		"cpu_request", "cpu_limit", "memory_request", "memory_limit"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for _, s := range r.samples {
+		record := []string{
+			s.Time.UTC().Format(time.RFC3339),
+			s.Namespace, s.Pod, s.Container,
+			format(s.Usage.Usage.CPU), format(s.Usage.Usage.Memory),
+			format(s.Requests.CPU), format(s.Limits.CPU),
+			format(s.Requests.Memory), format(s.Limits.Memory),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
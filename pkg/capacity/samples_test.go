@@ -0,0 +1,63 @@
+package capacity
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeStats(t *testing.T) {
+	values := make([]float64, 0, 20)
+	for i := 20; i >= 1; i-- {
+		values = append(values, float64(i))
+	}
+
+	stats := ComputeStats(values)
+	if stats.Min != 1 || stats.Max != 20 || stats.Avg != 10.5 || stats.P95 != 19 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	if (ComputeStats(nil) != Stats{}) {
+		t.Errorf("Expected zero stats for no values")
+	}
+	if single := ComputeStats([]float64{3}); single.P95 != 3 {
+		t.Errorf("Expected p95 of a single value to be the value, got %+v", single)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	var r Recorder
+	r.Add(start, []Usage{
+		{Namespace: "shop", Pod: "web-1", Usage: Resources{CPU: 0.1, Memory: 100}},
+		{Namespace: "shop", Pod: "db-0", Usage: Resources{CPU: 1, Memory: 1000}},
+	})
+	r.Add(start.Add(10*time.Second), []Usage{
+		{Namespace: "shop", Pod: "web-1", Usage: Resources{CPU: 0.3, Memory: 300}, Requests: Resources{CPU: 0.25}},
+	})
+
+	if r.Len() != 3 {
+		t.Fatalf("Expected 3 samples, got %d", r.Len())
+	}
+
+	summary := r.Summary()
+	if len(summary) != 2 || summary[0].Last.Pod != "db-0" {
+		t.Fatalf("Expected db-0 and web-1, got %+v", summary)
+	}
+	web := summary[1]
+	if web.Samples != 2 || web.CPU.Min != 0.1 || web.CPU.Max != 0.3 || web.Memory.Avg != 200 || web.Last.Requests.CPU != 0.25 {
+		t.Errorf("Unexpected web-1 summary: %+v", web)
+	}
+
+	var sb strings.Builder
+	if err := r.WriteCSV(&sb); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected header and 3 samples, got %q", sb.String())
+	}
+	if lines[3] != "2026-10-19T10:00:10Z,shop,web-1,,0.3,300,0.25,0,0,0" {
+		t.Errorf("Unexpected CSV line %q", lines[3])
+	}
+}
@@ -0,0 +1,170 @@
+package capacity
+
+import (
+	"sort"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// Sort keys for usage rows, in addition to SortName, SortCPU and SortMemory
+const (
+	SortCPURequest    = "cpu%req"
+	SortMemoryRequest = "mem%req"
+	SortCPULimit      = "cpu%limit"
+	SortMemoryLimit   = "mem%limit"
+)
+
+// UsageSortKeys lists the valid sort keys for usage rows
+var UsageSortKeys = []string{SortName, SortCPU, SortMemory, SortCPURequest, SortMemoryRequest, SortCPULimit, SortMemoryLimit}
+
+// Usage is the measured usage of a pod or container next to its requests
+// and limits. A zero request or limit means none is set.
+type Usage struct {
+	Namespace string
+	Pod       string
+	// Container is empty for pod totals
+	Container string
+	Usage     Resources
+	Requests  Resources
+	Limits    Resources
+}
+
+// Key identifies the row as namespace/pod or namespace/pod/container
+func (u Usage) Key() string {
+	key := u.Namespace + "/" + u.Pod
+	if u.Container != "" {
+		key += "/" + u.Container
+	}
+	return key
+}
+
+// CPURequestPercent returns CPU usage as a percentage of the request
+func (u Usage) CPURequestPercent() float64 {
+	return Percent(u.Usage.CPU, u.Requests.CPU)
+}
+
+// MemoryRequestPercent returns memory usage as a percentage of the request
+func (u Usage) MemoryRequestPercent() float64 {
+	return Percent(u.Usage.Memory, u.Requests.Memory)
+}
+
+// CPULimitPercent returns CPU usage as a percentage of the limit
+func (u Usage) CPULimitPercent() float64 {
+	return Percent(u.Usage.CPU, u.Limits.CPU)
+}
+
+// MemoryLimitPercent returns memory usage as a percentage of the limit
+func (u Usage) MemoryLimitPercent() float64 {
+	return Percent(u.Usage.Memory, u.Limits.Memory)
+}
+
+// MergeUsage joins pod metrics with the pod specs. With containers set it
+// returns one row per container, otherwise one row per pod. A pod has a
+// request or limit only when all its containers set it; a partial sum
+// would make the percentages misleading.
+func MergeUsage(metrics []kubernetes.PodMetrics, pods []kubernetes.Pod, containers bool) []Usage {
+	specs := map[string]kubernetes.PodSpec{}
+	for _, pod := range pods {
+		specs[pod.Metadata.Namespace+"/"+pod.Metadata.Name] = pod.Spec
+	}
+
+	var rows []Usage
+	for _, m := range metrics {
+		spec := specs[m.Metadata.Namespace+"/"+m.Metadata.Name]
+		specContainers := map[string]kubernetes.Container{}
+		for _, c := range spec.Containers {
+			specContainers[c.Name] = c
+		}
+
+		podRow := Usage{Namespace: m.Metadata.Namespace, Pod: m.Metadata.Name}
+		podRow.Requests, podRow.Limits = declaredTotals(spec.Containers)
+
+		for _, mc := range m.Containers {
+			usage := fromQuantities(mc.Usage)
+			podRow.Usage = podRow.Usage.Add(usage)
+
+			if containers {
+				row := Usage{Namespace: m.Metadata.Namespace, Pod: m.Metadata.Name, Container: mc.Name, Usage: usage}
+				if c, ok := specContainers[mc.Name]; ok {
+					row.Requests, row.Limits = ContainerResources(c)
+				}
+				rows = append(rows, row)
+			}
+		}
+
+		if !containers {
+			rows = append(rows, podRow)
+		}
+	}
+
+	SortUsage(rows, SortName)
+	return rows
+}
+
+// declaredTotals sums container requests and limits per resource, leaving
+// a resource at zero unless every container declares it
+func declaredTotals(containers []kubernetes.Container) (requests, limits Resources) {
+	if len(containers) == 0 {
+		return requests, limits
+	}
+
+	allRequests := map[string]bool{"cpu": true, "memory": true}
+	allLimits := map[string]bool{"cpu": true, "memory": true}
+	for _, c := range containers {
+		for resource := range allLimits {
+			_, hasLimit := c.Resources.Limits[resource]
+			_, hasRequest := c.Resources.Requests[resource]
+			allLimits[resource] = allLimits[resource] && hasLimit
+			allRequests[resource] = allRequests[resource] && (hasRequest || hasLimit)
+		}
+		req, lim := ContainerResources(c)
+		requests = requests.Add(req)
+		limits = limits.Add(lim)
+	}
+
+	if !allRequests["cpu"] {
+		requests.CPU = 0
+	}
+	if !allRequests["memory"] {
+		requests.Memory = 0
+	}
+	if !allLimits["cpu"] {
+		limits.CPU = 0
+	}
+	if !allLimits["memory"] {
+		limits.Memory = 0
+	}
+	return requests, limits
+}
+
+// SortUsage orders rows by name, or by a descending usage value. It
+// reports false for an unknown key.
+func SortUsage(rows []Usage, key string) bool {
+	var value func(u Usage) float64
+	switch key {
+	case SortName:
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Key() < rows[j].Key()
+		})
+		return true
+	case SortCPU:
+		value = func(u Usage) float64 { return u.Usage.CPU }
+	case SortMemory:
+		value = func(u Usage) float64 { return u.Usage.Memory }
+	case SortCPURequest:
+		value = Usage.CPURequestPercent
+	case SortMemoryRequest:
+		value = Usage.MemoryRequestPercent
+	case SortCPULimit:
+		value = Usage.CPULimitPercent
+	case SortMemoryLimit:
+		value = Usage.MemoryLimitPercent
+	default:
+		return false
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return value(rows[i]) > value(rows[j])
+	})
+	return true
+}
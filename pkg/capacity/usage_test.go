@@ -0,0 +1,88 @@
+package capacity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+const usagePods = `[
+	{"metadata": {"name": "web-1", "namespace": "shop"}, "spec": {"containers": [
+		{"name": "app", "resources": {"requests": {"cpu": "500m", "memory": "256Mi"}, "limits": {"cpu": "1", "memory": "512Mi"}}},
+		{"name": "proxy", "resources": {"requests": {"cpu": "100m"}}}
+	]}},
+	{"metadata": {"name": "db-0", "namespace": "shop"}, "spec": {"containers": [
+		{"name": "db", "resources": {"limits": {"cpu": "2", "memory": "1Gi"}}}
+	]}}
+]`
+
+const usageMetrics = `[
+	{"metadata": {"name": "web-1", "namespace": "shop"}, "containers": [
+		{"name": "app", "usage": {"cpu": "450m", "memory": "128Mi"}},
+		{"name": "proxy", "usage": {"cpu": "50m", "memory": "16Mi"}}
+	]},
+	{"metadata": {"name": "db-0", "namespace": "shop"}, "containers": [
+		{"name": "db", "usage": {"cpu": "1", "memory": "900Mi"}}
+	]}
+]`
+
+func TestMergeUsagePods(t *testing.T) {
+	var pods []kubernetes.Pod
+	if err := json.Unmarshal([]byte(usagePods), &pods); err != nil {
+		t.Fatalf("Failed to decode pods: %v", err)
+	}
+	var metrics []kubernetes.PodMetrics
+	if err := json.Unmarshal([]byte(usageMetrics), &metrics); err != nil {
+		t.Fatalf("Failed to decode metrics: %v", err)
+	}
+
+	rows := MergeUsage(metrics, pods, false)
+	if len(rows) != 2 || rows[0].Pod != "db-0" || rows[1].Pod != "web-1" {
+		t.Fatalf("Expected db-0 and web-1 sorted by name, got %+v", rows)
+	}
+
+	web := rows[1]
+	if web.Usage.CPU != 0.5 {
+		t.Errorf("Expected web-1 CPU usage 0.5, got %v", web.Usage.CPU)
+	}
+	if web.Requests.CPU != 0.6 || web.CPURequestPercent() < 83 || web.CPURequestPercent() > 84 {
+		t.Errorf("Expected web-1 CPU request 600m (83%%), got %v (%v%%)", web.Requests.CPU, web.CPURequestPercent())
+	}
+	// proxy sets no memory request or limit, so the pod has none
+	if web.Requests.Memory != 0 || web.Limits.CPU != 0 || web.Limits.Memory != 0 {
+		t.Errorf("Expected partial requests and limits to be dropped, got %+v / %+v", web.Requests, web.Limits)
+	}
+
+	db := rows[0]
+	if db.CPULimitPercent() != 50 || db.Requests.Memory != 1024*1024*1024 {
+		t.Errorf("Unexpected db-0 row: %+v", db)
+	}
+
+	if !SortUsage(rows, SortMemoryLimit) || rows[0].Pod != "db-0" {
+		t.Errorf("Expected db-0 first when sorting by mem%%limit")
+	}
+	if SortUsage(rows, "disk") {
+		t.Errorf("Expected unknown sort key to be rejected")
+	}
+}
+
+func TestMergeUsageContainers(t *testing.T) {
+	var pods []kubernetes.Pod
+	if err := json.Unmarshal([]byte(usagePods), &pods); err != nil {
+		t.Fatalf("Failed to decode pods: %v", err)
+	}
+	var metrics []kubernetes.PodMetrics
+	if err := json.Unmarshal([]byte(usageMetrics), &metrics); err != nil {
+		t.Fatalf("Failed to decode metrics: %v", err)
+	}
+
+	rows := MergeUsage(metrics, pods, true)
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 container rows, got %d", len(rows))
+	}
+	app := rows[1]
+	if app.Key() != "shop/web-1/app" || app.CPULimitPercent() != 45 || app.MemoryRequestPercent() != 50 {
+		t.Errorf("Unexpected app row: %+v", app)
+	}
+}
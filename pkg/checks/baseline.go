@@ -0,0 +1,184 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBaselineFile is the baseline read from the working directory
+const DefaultBaselineFile = ".kcsi-check.yaml"
+
+// expiresLayout is the date format of suppression expiry dates
+const expiresLayout = "2006-01-02"
+
+// Suppression hides the findings of a check for matching resources.
+// Namespace and Name accept glob patterns (e.g. "legacy-*"); empty fields
+// match everything.
+type Suppression struct {
+	Check     string `yaml:"check"`
+	Kind      string `yaml:"kind,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Name      string `yaml:"name,omitempty"`
+	Expires   string `yaml:"expires,omitempty"`
+	Reason    string `yaml:"reason,omitempty"`
+}
+
+// Baseline is the content of a .kcsi-check.yaml file
+type Baseline struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// LoadBaseline reads a baseline file. A missing file is an empty baseline.
+func LoadBaseline(filePath string) (*Baseline, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Baseline{}, nil
+		}
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := yaml.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", filePath, err)
+	}
+
+	for i, s := range baseline.Suppressions {
+		if s.Check == "" {
+			return nil, fmt.Errorf("baseline %s: suppression %d has no check ID", filePath, i+1)
+		}
+		if s.Expires != "" {
+			if _, err := time.Parse(expiresLayout, s.Expires); err != nil {
+				return nil, fmt.Errorf("baseline %s: suppression %d has invalid expiry '%s' (expected YYYY-MM-DD)", filePath, i+1, s.Expires)
+			}
+		}
+		for _, pattern := range []string{s.Namespace, s.Name} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("baseline %s: suppression %d has invalid pattern '%s'", filePath, i+1, pattern)
+			}
+		}
+	}
+
+	return &baseline, nil
+}
+
+// Save writes the baseline file
+func (b *Baseline) Save(filePath string) error {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Expired reports whether the suppression is past its expiry date. A
+// suppression stays valid during the whole expiry day.
+func (s Suppression) Expired(now time.Time) bool {
+	if s.Expires == "" {
+		return false
+	}
+	expires, err := time.ParseInLocation(expiresLayout, s.Expires, now.Location())
+	if err != nil {
+		return false
+	}
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+// Matches reports whether the suppression applies to a finding
+func (s Suppression) Matches(finding Finding) bool {
+	if s.Check != "*" && s.Check != finding.CheckID {
+		return false
+	}
+	if s.Kind != "" && s.Kind != finding.Resource.Kind {
+		return false
+	}
+	return globMatch(s.Namespace, finding.Resource.Namespace) && globMatch(s.Name, finding.Resource.Name)
+}
+
+func globMatch(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// Apply removes suppressed findings from the results. It returns the
+// filtered results, the number of suppressed findings and the expired
+// suppressions, which no longer apply.
+func (b *Baseline) Apply(results []Result, now time.Time) ([]Result, int, []Suppression) {
+	var active, expired []Suppression
+	for _, s := range b.Suppressions {
+		if s.Expired(now) {
+			expired = append(expired, s)
+		} else {
+			active = append(active, s)
+		}
+	}
+
+	suppressed := 0
+	filtered := make([]Result, 0, len(results))
+	for _, result := range results {
+		var kept []Finding
+		for _, finding := range result.Findings {
+			if matchesAny(active, finding) {
+				suppressed++
+				continue
+			}
+			kept = append(kept, finding)
+		}
+		filtered = append(filtered, Result{Check: result.Check, Findings: kept})
+	}
+
+	return filtered, suppressed, expired
+}
+
+// Add appends a suppression for each finding not already covered by an
+// active suppression and returns the number added. Expired suppressions
+// are dropped from the baseline.
+func (b *Baseline) Add(results []Result, reason, expires string, now time.Time) int {
+	var active []Suppression
+	for _, s := range b.Suppressions {
+		if !s.Expired(now) {
+			active = append(active, s)
+		}
+	}
+	b.Suppressions = append([]Suppression{}, active...)
+
+	added := 0
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if matchesAny(active, finding) {
+				continue
+			}
+			suppression := Suppression{
+				Check:     finding.CheckID,
+				Kind:      finding.Resource.Kind,
+				Namespace: finding.Resource.Namespace,
+				Name:      finding.Resource.Name,
+				Expires:   expires,
+				Reason:    reason,
+			}
+			b.Suppressions = append(b.Suppressions, suppression)
+			active = append(active, suppression)
+			added++
+		}
+	}
+	return added
+}
+
+func matchesAny(suppressions []Suppression, finding Finding) bool {
+	for _, s := range suppressions {
+		if s.Matches(finding) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func baselineResults() []Result {
+	pdb := Check{ID: "single-replica-no-pdb", Severity: SeverityWarning}
+	tags := Check{ID: "latest-image-tag", Severity: SeverityWarning}
+	return []Result{
+		{Check: pdb, Findings: []Finding{
+			{CheckID: pdb.ID, Resource: ResourceRef{Kind: "Deployment", Namespace: "legacy", Name: "billing-api"}},
+			{CheckID: pdb.ID, Resource: ResourceRef{Kind: "Deployment", Namespace: "shop", Name: "web"}},
+		}},
+		{Check: tags, Findings: []Finding{
+			{CheckID: tags.ID, Resource: ResourceRef{Kind: "Deployment", Namespace: "legacy", Name: "billing-api"}},
+		}},
+	}
+}
+
+func TestSuppressionMatches(t *testing.T) {
+	finding := Finding{CheckID: "single-replica-no-pdb", Resource: ResourceRef{Kind: "Deployment", Namespace: "legacy", Name: "billing-api"}}
+
+	tests := []struct {
+		suppression Suppression
+		expected    bool
+	}{
+		{Suppression{Check: "single-replica-no-pdb"}, true},
+		{Suppression{Check: "single-replica-no-pdb", Namespace: "legacy", Name: "billing-*"}, true},
+		{Suppression{Check: "single-replica-no-pdb", Namespace: "shop"}, false},
+		{Suppression{Check: "single-replica-no-pdb", Kind: "StatefulSet"}, false},
+		{Suppression{Check: "latest-image-tag"}, false},
+		{Suppression{Check: "*", Namespace: "leg*"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.suppression.Matches(finding); got != tt.expected {
+			t.Errorf("%+v.Matches() = %v, expected %v", tt.suppression, got, tt.expected)
+		}
+	}
+}
+
+func TestSuppressionExpired(t *testing.T) {
+	s := Suppression{Check: "x", Expires: "2026-01-09"}
+	if s.Expired(time.Date(2026, 1, 9, 23, 59, 0, 0, time.UTC)) {
+		t.Error("Expected suppression to be valid during its expiry day")
+	}
+	if !s.Expired(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected suppression to be expired the day after")
+	}
+	if (Suppression{Check: "x"}).Expired(time.Now()) {
+		t.Error("Expected suppression without expiry to never expire")
+	}
+}
+
+func TestBaselineApply(t *testing.T) {
+	now := time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)
+	baseline := Baseline{Suppressions: []Suppression{
+		{Check: "single-replica-no-pdb", Namespace: "legacy", Name: "billing-*", Reason: "accepted risk"},
+		{Check: "latest-image-tag", Namespace: "legacy", Expires: "2026-01-01"},
+	}}
+
+	filtered, suppressed, expired := baseline.Apply(baselineResults(), now)
+	if suppressed != 1 {
+		t.Errorf("Expected 1 suppressed finding, got %d", suppressed)
+	}
+	if len(expired) != 1 || expired[0].Check != "latest-image-tag" {
+		t.Errorf("Expected the latest-image-tag suppression to be expired, got %+v", expired)
+	}
+	if len(filtered[0].Findings) != 1 || filtered[0].Findings[0].Resource.Name != "web" {
+		t.Errorf("Unexpected remaining findings: %+v", filtered[0].Findings)
+	}
+	if len(filtered[1].Findings) != 1 {
+		t.Errorf("Expected expired suppression not to apply, got %+v", filtered[1].Findings)
+	}
+}
+
+func TestBaselineAddAndRoundTrip(t *testing.T) {
+	now := time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)
+	baseline := Baseline{Suppressions: []Suppression{
+		{Check: "single-replica-no-pdb", Namespace: "legacy"},
+		{Check: "latest-image-tag", Expires: "2025-12-31"},
+	}}
+
+	added := baseline.Add(baselineResults(), "baseline", "2026-06-30", now)
+	if added != 2 {
+		t.Errorf("Expected 2 suppressions added, got %d", added)
+	}
+	if len(baseline.Suppressions) != 3 {
+		t.Fatalf("Expected expired suppression dropped and 3 left, got %+v", baseline.Suppressions)
+	}
+
+	path := filepath.Join(t.TempDir(), DefaultBaselineFile)
+	if err := baseline.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Suppressions) != 3 || loaded.Suppressions[1].Expires != "2026-06-30" || loaded.Suppressions[1].Reason != "baseline" {
+		t.Errorf("Unexpected loaded baseline: %+v", loaded.Suppressions)
+	}
+
+	_, suppressed, _ := loaded.Apply(baselineResults(), now)
+	if suppressed != 3 {
+		t.Errorf("Expected all findings suppressed after writing the baseline, got %d", suppressed)
+	}
+}
+
+func TestLoadBaselineErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	missing, err := LoadBaseline(filepath.Join(dir, "missing.yaml"))
+	if err != nil || len(missing.Suppressions) != 0 {
+		t.Errorf("Expected empty baseline for a missing file, got %+v (%v)", missing, err)
+	}
+
+	invalid := map[string]string{
+		"no-check.yaml":    "suppressions:\n  - namespace: shop\n",
+		"bad-expiry.yaml":  "suppressions:\n  - check: x\n    expires: next week\n",
+		"bad-pattern.yaml": "suppressions:\n  - check: x\n    name: \"[\"\n",
+	}
+	for name, content := range invalid {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadBaseline(path); err == nil || !strings.Contains(err.Error(), "suppression 1") {
+			t.Errorf("Expected validation error for %s, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package checks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stanzinofree/kcsi/pkg/diagnose"
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// stuckNamespaceAfter is how long a namespace may stay Terminating before it is reported
+const stuckNamespaceAfter = 10 * time.Minute
+
+// podRestartThreshold is the restart count from which the pod-health check reports a container
+const podRestartThreshold = 5
+
+// now is replaced in tests
+var now = time.Now
+
+func init() {
+	Register(Check{
+		ID:          "node-conditions",
+		Severity:    SeverityError,
+		Description: "Nodes that are not ready or report memory, disk, PID or network pressure",
+		Requires:    []Resource{ResourceNodes},
+		Run:         checkNodeConditions,
+	})
+	Register(Check{
+		ID:          "unbound-pvcs",
+		Severity:    SeverityWarning,
+		Description: "PersistentVolumeClaims that are not bound to a volume",
+		Requires:    []Resource{ResourcePVCs},
+		Run:         checkUnboundPVCs,
+	})
+	Register(Check{
+		ID:          "pod-resource-limits",
+		Severity:    SeverityWarning,
+		Description: "Containers without CPU or memory limits",
+		Requires:    []Resource{ResourcePods},
+		Run:         checkResourceLimits,
+	})
+	Register(Check{
+		ID:          "missing-probes",
+		Severity:    SeverityWarning,
+		Description: "Long-running containers without liveness or readiness probes",
+		Requires:    []Resource{ResourcePods},
+		Run:         checkMissingProbes,
+	})
+	Register(Check{
+		ID:          "latest-image-tag",
+		Severity:    SeverityWarning,
+		Description: "Containers running images tagged :latest or without a tag",
+		Requires:    []Resource{ResourcePods},
+		Run:         checkLatestImageTag,
+	})
+	Register(Check{
+		ID:          "single-replica-no-pdb",
+		Severity:    SeverityWarning,
+		Description: "Single-replica deployments not covered by a PodDisruptionBudget",
+		Requires:    []Resource{ResourceDeployments, ResourcePDBs},
+		Run:         checkSingleReplicaWithoutPDB,
+	})
+	Register(Check{
+		ID:          "terminating-namespaces",
+		Severity:    SeverityError,
+		Description: fmt.Sprintf("Namespaces stuck in Terminating for more than %s", stuckNamespaceAfter),
+		Requires:    []Resource{ResourceNamespaces},
+		Run:         checkTerminatingNamespaces,
+	})
+	Register(Check{
+		ID:          "pod-health",
+		Severity:    SeverityError,
+		Description: "Pods crashlooping, failing to pull images, unschedulable, evicted, failed or not ready",
+		Requires:    []Resource{ResourcePods},
+		Run:         checkPodHealth,
+	})
+}
+
+func checkNodeConditions(state *State) []Finding {
+	var findings []Finding
+	for _, node := range state.Nodes {
+		ref := ResourceRef{Kind: "Node", Name: node.Metadata.Name}
+
+		if !node.IsReady() {
+			message := "node is not ready"
+			for _, cond := range node.Status.Conditions {
+				if cond.Type == "Ready" && cond.Message != "" {
+					message = fmt.Sprintf("node is not ready: %s", cond.Message)
+				}
+			}
+			findings = append(findings, Finding{Resource: ref, Message: message})
+		}
+
+		for _, cond := range node.PressureConditions() {
+			findings = append(findings, Finding{Resource: ref, Message: fmt.Sprintf("%s: %s", cond.Type, cond.Message)})
+		}
+	}
+	return findings
+}
+
+func checkUnboundPVCs(state *State) []Finding {
+	var findings []Finding
+	for _, pvc := range state.PVCs {
+		if pvc.Status.Phase == "Bound" {
+			continue
+		}
+		message := fmt.Sprintf("claim is %s", pvc.Status.Phase)
+		if pvc.Spec.StorageClassName != nil {
+			message += fmt.Sprintf(" (storage class %s)", *pvc.Spec.StorageClassName)
+		}
+		findings = append(findings, Finding{
+			Resource: ResourceRef{Kind: "PersistentVolumeClaim", Namespace: pvc.Metadata.Namespace, Name: pvc.Metadata.Name},
+			Message:  message,
+		})
+	}
+	return findings
+}
+
+func checkResourceLimits(state *State) []Finding {
+	return perWorkload(state.Pods, func(pod kubernetes.Pod) []string {
+		var problems []string
+		for _, container := range pod.Spec.Containers {
+			var missing []string
+			for _, resource := range []string{"cpu", "memory"} {
+				if _, ok := container.Resources.Limits[resource]; !ok {
+					missing = append(missing, resource)
+				}
+			}
+			if len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("container %s has no %s limit", container.Name, strings.Join(missing, "/")))
+			}
+		}
+		return problems
+	})
+}
+
+func checkMissingProbes(state *State) []Finding {
+	return perWorkload(state.Pods, func(pod kubernetes.Pod) []string {
+		// Jobs run to completion and are not probed
+		if ownerKind(pod) == "Job" || pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+			return nil
+		}
+
+		var problems []string
+		for _, container := range pod.Spec.Containers {
+			var missing []string
+			if container.LivenessProbe == nil {
+				missing = append(missing, "liveness")
+			}
+			if container.ReadinessProbe == nil {
+				missing = append(missing, "readiness")
+			}
+			if len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("container %s has no %s probe", container.Name, strings.Join(missing, "/")))
+			}
+		}
+		return problems
+	})
+}
+
+func checkLatestImageTag(state *State) []Finding {
+	return perWorkload(state.Pods, func(pod kubernetes.Pod) []string {
+		var problems []string
+		containers := append(append([]kubernetes.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			if usesLatestTag(container.Image) {
+				problems = append(problems, fmt.Sprintf("container %s uses image %s", container.Name, container.Image))
+			}
+		}
+		return problems
+	})
+}
+
+// usesLatestTag reports whether an image reference resolves to the :latest tag
+func usesLatestTag(image string) bool {
+	if image == "" || strings.Contains(image, "@") {
+		return false
+	}
+	// The tag follows the last colon after the last slash (a colon before
+	// it belongs to the registry port)
+	name := image[strings.LastIndex(image, "/")+1:]
+	colon := strings.LastIndex(name, ":")
+	return colon == -1 || name[colon+1:] == "latest"
+}
+
+func checkSingleReplicaWithoutPDB(state *State) []Finding {
+	var findings []Finding
+	for _, deployment := range state.Deployments {
+		if deployment.DesiredReplicas() != 1 {
+			continue
+		}
+
+		covered := false
+		for _, pdb := range state.PDBs {
+			if pdb.Metadata.Namespace == deployment.Metadata.Namespace && pdb.Spec.Selector.Matches(deployment.Spec.Template.Metadata.Labels) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Resource: ResourceRef{Kind: "Deployment", Namespace: deployment.Metadata.Namespace, Name: deployment.Metadata.Name},
+			Message:  "runs a single replica without a PodDisruptionBudget, node drains cause downtime",
+		})
+	}
+	return findings
+}
+
+func checkTerminatingNamespaces(state *State) []Finding {
+	var findings []Finding
+	for _, ns := range state.Namespaces {
+		if state.Namespace != "" && ns.Metadata.Name != state.Namespace {
+			continue
+		}
+		if ns.Status.Phase != "Terminating" || ns.Metadata.DeletionTimestamp == nil {
+			continue
+		}
+		stuckFor := now().Sub(*ns.Metadata.DeletionTimestamp)
+		if stuckFor < stuckNamespaceAfter {
+			continue
+		}
+
+		message := fmt.Sprintf("terminating for %s", stuckFor.Round(time.Minute))
+		for _, cond := range ns.Status.Conditions {
+			if cond.Status == "True" && cond.Message != "" {
+				message += "; " + cond.Message
+			}
+		}
+		findings = append(findings, Finding{
+			Resource: ResourceRef{Kind: "Namespace", Name: ns.Metadata.Name},
+			Message:  message,
+		})
+	}
+	return findings
+}
+
+func checkPodHealth(state *State) []Finding {
+	var findings []Finding
+	for _, pod := range state.Pods {
+		for _, problem := range diagnose.PodProblems(pod, podRestartThreshold) {
+			findings = append(findings, PodProblemFinding(pod, problem))
+		}
+	}
+	return findings
+}
+
+// PodProblemFinding reports a problem found by diagnose.PodProblems
+func PodProblemFinding(pod kubernetes.Pod, problem diagnose.PodProblem) Finding {
+	message := fmt.Sprintf("%s: %s", problem.Category, problem.Reason)
+	if problem.Container != "" {
+		message = fmt.Sprintf("%s: container %s %s", problem.Category, problem.Container, problem.Reason)
+	}
+	return Finding{
+		Resource: ResourceRef{Kind: "Pod", Namespace: pod.Metadata.Namespace, Name: pod.Metadata.Name},
+		Message:  message,
+	}
+}
+
+// perWorkload runs a pod spec check once per owning workload so replicas of
+// the same deployment are reported once
+func perWorkload(pods []kubernetes.Pod, inspect func(pod kubernetes.Pod) []string) []Finding {
+	seen := map[ResourceRef]bool{}
+	var findings []Finding
+	for _, pod := range pods {
+		ref := workloadRef(pod)
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		for _, problem := range inspect(pod) {
+			findings = append(findings, Finding{Resource: ref, Message: problem})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Resource.String() < findings[j].Resource.String()
+	})
+	return findings
+}
+
+// workloadRef returns the controller that owns a pod, resolving replicasets
+// created by deployments to the deployment itself
+func workloadRef(pod kubernetes.Pod) ResourceRef {
+	for _, owner := range pod.Metadata.OwnerReferences {
+		if owner.Kind == "ReplicaSet" {
+			if hash := pod.Metadata.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+				return ResourceRef{Kind: "Deployment", Namespace: pod.Metadata.Namespace, Name: strings.TrimSuffix(owner.Name, "-"+hash)}
+			}
+		}
+		return ResourceRef{Kind: owner.Kind, Namespace: pod.Metadata.Namespace, Name: owner.Name}
+	}
+	return ResourceRef{Kind: "Pod", Namespace: pod.Metadata.Namespace, Name: pod.Metadata.Name}
+}
+
+func ownerKind(pod kubernetes.Pod) string {
+	if len(pod.Metadata.OwnerReferences) == 0 {
+		return ""
+	}
+	return pod.Metadata.OwnerReferences[0].Kind
+}
@@ -0,0 +1,164 @@
+package checks
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+func TestCheckNodeConditions(t *testing.T) {
+	var state State
+	data := `{"Nodes": [
+		{"metadata": {"name": "node-1"}, "status": {"conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"name": "node-2"}, "status": {"conditions": [{"type": "Ready", "status": "False", "message": "kubelet stopped posting status"}]}},
+		{"metadata": {"name": "node-3"}, "status": {"conditions": [{"type": "Ready", "status": "True"}, {"type": "DiskPressure", "status": "True", "message": "disk usage 92%"}]}}
+	]}`
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatalf("Failed to decode nodes fixture: %v", err)
+	}
+
+	findings := checkNodeConditions(&state)
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Resource.Name != "node-2" || !strings.Contains(findings[0].Message, "not ready") {
+		t.Errorf("Unexpected finding: %+v", findings[0])
+	}
+	if findings[1].Resource.Name != "node-3" || !strings.Contains(findings[1].Message, "DiskPressure") {
+		t.Errorf("Unexpected finding: %+v", findings[1])
+	}
+}
+
+func TestCheckUnboundPVCs(t *testing.T) {
+	var state State
+	data := `{"PVCs": [
+		{"metadata": {"name": "data-0", "namespace": "db"}, "status": {"phase": "Bound"}},
+		{"metadata": {"name": "data-1", "namespace": "db"}, "spec": {"storageClassName": "fast"}, "status": {"phase": "Pending"}}
+	]}`
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatalf("Failed to decode PVCs fixture: %v", err)
+	}
+
+	findings := checkUnboundPVCs(&state)
+	if len(findings) != 1 || findings[0].Resource.Name != "data-1" || !strings.Contains(findings[0].Message, "fast") {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+}
+
+func TestPodSpecChecksGroupByWorkload(t *testing.T) {
+	var state State
+	data := `{"Pods": [
+		{"metadata": {"name": "web-abc-1", "namespace": "shop", "labels": {"pod-template-hash": "abc"},
+			"ownerReferences": [{"kind": "ReplicaSet", "name": "web-abc"}]},
+		 "spec": {"containers": [{"name": "app", "image": "shop/web", "resources": {"limits": {"cpu": "1"}}}]}},
+		{"metadata": {"name": "web-abc-2", "namespace": "shop", "labels": {"pod-template-hash": "abc"},
+			"ownerReferences": [{"kind": "ReplicaSet", "name": "web-abc"}]},
+		 "spec": {"containers": [{"name": "app", "image": "shop/web", "resources": {"limits": {"cpu": "1"}}}]}},
+		{"metadata": {"name": "migrate-x", "namespace": "shop", "ownerReferences": [{"kind": "Job", "name": "migrate"}]},
+		 "spec": {"containers": [{"name": "migrate", "image": "registry:5000/shop/migrate:1.0",
+			"resources": {"limits": {"cpu": "1", "memory": "1Gi"}}}]}}
+	]}`
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatalf("Failed to decode pods fixture: %v", err)
+	}
+
+	limits := checkResourceLimits(&state)
+	if len(limits) != 1 || limits[0].Resource.String() != "shop/Deployment/web" || !strings.Contains(limits[0].Message, "no memory limit") {
+		t.Errorf("Unexpected resource limit findings: %+v", limits)
+	}
+
+	probes := checkMissingProbes(&state)
+	if len(probes) != 1 || !strings.Contains(probes[0].Message, "liveness/readiness") {
+		t.Errorf("Unexpected probe findings: %+v", probes)
+	}
+
+	latest := checkLatestImageTag(&state)
+	if len(latest) != 1 || latest[0].Resource.Name != "web" {
+		t.Errorf("Unexpected latest tag findings: %+v", latest)
+	}
+}
+
+func TestUsesLatestTag(t *testing.T) {
+	tests := map[string]bool{
+		"nginx":                         true,
+		"nginx:latest":                  true,
+		"nginx:1.25":                    false,
+		"registry:5000/team/app":        true,
+		"registry:5000/team/app:v2":     false,
+		"nginx@sha256:0123456789abcdef": false,
+		"ghcr.io/org/app:latest":        true,
+	}
+	for image, expected := range tests {
+		if got := usesLatestTag(image); got != expected {
+			t.Errorf("usesLatestTag(%q) = %v, expected %v", image, got, expected)
+		}
+	}
+}
+
+func TestCheckSingleReplicaWithoutPDB(t *testing.T) {
+	var state State
+	data := `{
+		"Deployments": [
+			{"metadata": {"name": "api", "namespace": "shop"}, "spec": {"replicas": 1, "template": {"metadata": {"labels": {"app": "api"}}}}},
+			{"metadata": {"name": "web", "namespace": "shop"}, "spec": {"replicas": 1, "template": {"metadata": {"labels": {"app": "web"}}}}},
+			{"metadata": {"name": "cart", "namespace": "shop"}, "spec": {"replicas": 3, "template": {"metadata": {"labels": {"app": "cart"}}}}}
+		],
+		"PDBs": [
+			{"metadata": {"name": "web", "namespace": "shop"}, "spec": {"selector": {"matchLabels": {"app": "web"}}}}
+		]
+	}`
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatalf("Failed to decode deployments and PDBs fixture: %v", err)
+	}
+
+	findings := checkSingleReplicaWithoutPDB(&state)
+	if len(findings) != 1 || findings[0].Resource.Name != "api" {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+}
+
+func TestCheckTerminatingNamespaces(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	var state State
+	data := `{"Namespaces": [
+		{"metadata": {"name": "old", "deletionTimestamp": "2026-01-09T10:00:00Z"}, "status": {"phase": "Terminating",
+			"conditions": [{"type": "NamespaceFinalizersRemaining", "status": "True", "message": "Some content has finalizers remaining"}]}},
+		{"metadata": {"name": "recent", "deletionTimestamp": "2026-01-09T11:58:00Z"}, "status": {"phase": "Terminating"}},
+		{"metadata": {"name": "default"}, "status": {"phase": "Active"}}
+	]}`
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatalf("Failed to decode namespaces fixture: %v", err)
+	}
+
+	findings := checkTerminatingNamespaces(&state)
+	if len(findings) != 1 || findings[0].Resource.Name != "old" {
+		t.Fatalf("Unexpected findings: %+v", findings)
+	}
+	if !strings.Contains(findings[0].Message, "2h0m0s") || !strings.Contains(findings[0].Message, "finalizers") {
+		t.Errorf("Unexpected message: %s", findings[0].Message)
+	}
+
+	state.Namespace = "other"
+	if findings := checkTerminatingNamespaces(&state); len(findings) != 0 {
+		t.Errorf("Expected namespace scoping to skip 'old', got %+v", findings)
+	}
+}
+
+func TestCheckPodHealth(t *testing.T) {
+	state := State{Pods: []kubernetes.Pod{{}}}
+	data := `{"metadata": {"name": "web-1", "namespace": "shop"}, "status": {"phase": "Running",
+		"containerStatuses": [{"name": "app", "state": {"waiting": {"reason": "CrashLoopBackOff"}}}]}}`
+	if err := json.Unmarshal([]byte(data), &state.Pods[0]); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+
+	findings := checkPodHealth(&state)
+	if len(findings) != 1 || findings[0].Message != "crashlooping: container app CrashLoopBackOff" {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+}
@@ -0,0 +1,236 @@
+// Package checks provides a registry of cluster health checks run by
+// `kcsi check`. Each check declares the resources it needs, which are
+// loaded once into a State shared by all checks of a run.
+package checks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// Severity ranks how serious a finding is
+type Severity int
+
+// Severities from least to most serious
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText renders the severity by name in JSON and YAML
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ExitCode is the process exit code of a run whose highest finding has
+// this severity: 2 for info, 3 for warning and 4 for error. 0 means no
+// failing finding and 1 is left to operational errors.
+func (s Severity) ExitCode() int {
+	return int(s) + 2
+}
+
+// ParseSeverity converts a severity name to a Severity
+func ParseSeverity(name string) (Severity, error) {
+	for _, s := range []Severity{SeverityInfo, SeverityWarning, SeverityError} {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity '%s' (valid: info, warning, error)", name)
+}
+
+// Resource is a kind of cluster object a check reads
+type Resource string
+
+// Resources that can be loaded into a State
+const (
+	ResourceNodes       Resource = "nodes"
+	ResourcePods        Resource = "pods"
+	ResourcePVCs        Resource = "persistentvolumeclaims"
+	ResourceDeployments Resource = "deployments"
+	ResourcePDBs        Resource = "poddisruptionbudgets"
+	ResourceNamespaces  Resource = "namespaces"
+)
+
+// ResourceRef identifies the object a finding is about
+type ResourceRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (r ResourceRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Namespace + "/" + r.Kind + "/" + r.Name
+}
+
+// Finding is a single problem reported by a check
+type Finding struct {
+	CheckID  string      `json:"check_id"`
+	Severity Severity    `json:"severity"`
+	Resource ResourceRef `json:"resource"`
+	Message  string      `json:"message"`
+}
+
+// Check is a cluster health check
+type Check struct {
+	ID          string
+	Severity    Severity
+	Description string
+	// Requires lists the resources Run reads from the state
+	Requires []Resource
+	// Run returns the findings of the check; their severity and check ID
+	// are filled in by the registry
+	Run func(state *State) []Finding
+}
+
+// State is the snapshot of the cluster the checks run against
+type State struct {
+	// Namespace scopes namespaced resources; empty means all namespaces
+	Namespace   string
+	Nodes       []kubernetes.Node
+	Pods        []kubernetes.Pod
+	PVCs        []kubernetes.PersistentVolumeClaim
+	Deployments []kubernetes.Workload
+	PDBs        []kubernetes.PodDisruptionBudget
+	Namespaces  []kubernetes.Namespace
+}
+
+var registry = map[string]Check{}
+
+// Register adds a check to the registry. It panics on a duplicate ID, which
+// is a programming error.
+func Register(check Check) {
+	if _, exists := registry[check.ID]; exists {
+		panic(fmt.Sprintf("check %s registered twice", check.ID))
+	}
+	registry[check.ID] = check
+}
+
+// All returns every registered check sorted by ID
+func All() []Check {
+	checks := make([]Check, 0, len(registry))
+	for _, check := range registry {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].ID < checks[j].ID
+	})
+	return checks
+}
+
+// Get returns the registered check with an ID
+func Get(id string) (Check, bool) {
+	check, ok := registry[id]
+	return check, ok
+}
+
+// Select returns the checks with the given IDs, or every check for "all"
+func Select(ids []string) ([]Check, error) {
+	var selected []Check
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if id == "all" {
+			return All(), nil
+		}
+		check, ok := Get(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown check '%s' (run 'kcsi check list' to see available checks)", id)
+		}
+		if !seen[id] {
+			selected = append(selected, check)
+			seen[id] = true
+		}
+	}
+	return selected, nil
+}
+
+// LoadState fetches the resources required by the checks. Cluster-scoped
+// resources (nodes, namespaces) are always loaded in full.
+func LoadState(namespace string, checks []Check) (*State, error) {
+	state := &State{Namespace: namespace}
+
+	required := map[Resource]bool{}
+	for _, check := range checks {
+		for _, resource := range check.Requires {
+			required[resource] = true
+		}
+	}
+
+	var err error
+	if required[ResourceNodes] {
+		if state.Nodes, err = kubernetes.ListNodes(); err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %v", err)
+		}
+	}
+	if required[ResourcePods] {
+		if state.Pods, err = kubernetes.ListPods(namespace, ""); err != nil {
+			return nil, fmt.Errorf("failed to list pods: %v", err)
+		}
+	}
+	if required[ResourcePVCs] {
+		if state.PVCs, err = kubernetes.ListPVCs(namespace); err != nil {
+			return nil, fmt.Errorf("failed to list persistent volume claims: %v", err)
+		}
+	}
+	if required[ResourceDeployments] {
+		if state.Deployments, err = kubernetes.ListWorkloads("deployments", namespace); err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %v", err)
+		}
+	}
+	if required[ResourcePDBs] {
+		if state.PDBs, err = kubernetes.ListPDBs(namespace); err != nil {
+			return nil, fmt.Errorf("failed to list pod disruption budgets: %v", err)
+		}
+	}
+	if required[ResourceNamespaces] {
+		if state.Namespaces, err = kubernetes.ListNamespaces(); err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %v", err)
+		}
+	}
+
+	return state, nil
+}
+
+// Result holds the findings of one check
+type Result struct {
+	Check    Check
+	Findings []Finding
+}
+
+// NewResult returns the result of a check, filling in the check ID and
+// severity of its findings
+func NewResult(check Check, findings []Finding) Result {
+	for i := range findings {
+		findings[i].CheckID = check.ID
+		findings[i].Severity = check.Severity
+	}
+	return Result{Check: check, Findings: findings}
+}
+
+// Run executes the checks against a state
+func Run(checks []Check, state *State) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, NewResult(check, check.Run(state)))
+	}
+	return results
+}
@@ -0,0 +1,87 @@
+package checks
+
+import (
+	"testing"
+)
+
+func TestBuiltinChecksRegistered(t *testing.T) {
+	expected := []string{
+		"latest-image-tag",
+		"missing-probes",
+		"node-conditions",
+		"pod-health",
+		"pod-resource-limits",
+		"single-replica-no-pdb",
+		"terminating-namespaces",
+		"unbound-pvcs",
+	}
+
+	all := All()
+	if len(all) != len(expected) {
+		t.Fatalf("Expected %d checks, got %d", len(expected), len(all))
+	}
+	for i, check := range all {
+		if check.ID != expected[i] {
+			t.Errorf("Expected check %s at position %d, got %s", expected[i], i, check.ID)
+		}
+		if check.Description == "" || check.Run == nil || len(check.Requires) == 0 {
+			t.Errorf("Check %s is incomplete", check.ID)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	selected, err := Select([]string{"unbound-pvcs", "node-conditions", "unbound-pvcs"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(selected) != 2 || selected[0].ID != "unbound-pvcs" || selected[1].ID != "node-conditions" {
+		t.Errorf("Unexpected selection: %+v", selected)
+	}
+
+	all, err := Select([]string{"all"})
+	if err != nil || len(all) != len(All()) {
+		t.Errorf("Expected all checks, got %d (err %v)", len(all), err)
+	}
+
+	if _, err := Select([]string{"no-such-check"}); err == nil {
+		t.Error("Expected error for unknown check")
+	}
+}
+
+func TestRunFillsCheckFields(t *testing.T) {
+	check := Check{
+		ID:       "test-check",
+		Severity: SeverityWarning,
+		Run: func(_ *State) []Finding {
+			return []Finding{{Resource: ResourceRef{Kind: "Pod", Namespace: "shop", Name: "web-1"}, Message: "broken"}}
+		},
+	}
+
+	results := Run([]Check{check}, &State{})
+	if len(results) != 1 || len(results[0].Findings) != 1 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	finding := results[0].Findings[0]
+	if finding.CheckID != "test-check" || finding.Severity != SeverityWarning {
+		t.Errorf("Expected check ID and severity to be filled, got %+v", finding)
+	}
+	if finding.Resource.String() != "shop/Pod/web-1" {
+		t.Errorf("Unexpected resource ref %s", finding.Resource)
+	}
+}
+
+func TestSeverityString(t *testing.T) {
+	if SeverityInfo.String() != "info" || SeverityWarning.String() != "warning" || SeverityError.String() != "error" {
+		t.Error("Unexpected severity names")
+	}
+}
+
+func TestSeverityExitCode(t *testing.T) {
+	codes := map[Severity]int{SeverityInfo: 2, SeverityWarning: 3, SeverityError: 4}
+	for severity, expected := range codes {
+		if got := severity.ExitCode(); got != expected {
+			t.Errorf("Expected exit code %d for %s, got %d", expected, severity, got)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package checks
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Report output formats
+const (
+	FormatJSON     = "json"
+	FormatJUnit    = "junit"
+	FormatSARIF    = "sarif"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the machine-readable report formats
+var Formats = []string{FormatJSON, FormatJUnit, FormatSARIF, FormatMarkdown}
+
+// sarifSchema is the JSON schema of SARIF 2.1.0 reports
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// Report is the outcome of a check run
+type Report struct {
+	// Namespace the checks were scoped to; empty means all namespaces
+	Namespace   string
+	GeneratedAt time.Time
+	ToolVersion string
+	Results     []Result
+	// Suppressed is the number of findings hidden by the baseline
+	Suppressed int
+}
+
+// Highest returns the highest severity among all findings, and false when
+// there are no findings
+func (r Report) Highest() (Severity, bool) {
+	highest, found := SeverityInfo, false
+	for _, result := range r.Results {
+		for _, finding := range result.Findings {
+			if !found || finding.Severity > highest {
+				highest, found = finding.Severity, true
+			}
+		}
+	}
+	return highest, found
+}
+
+// Counts returns the number of findings per severity
+func (r Report) Counts() map[Severity]int {
+	counts := map[Severity]int{}
+	for _, result := range r.Results {
+		for _, finding := range result.Findings {
+			counts[finding.Severity]++
+		}
+	}
+	return counts
+}
+
+// Write renders the report in one of the machine-readable formats
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		return r.writeJSON(w)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	case FormatSARIF:
+		return r.writeSARIF(w)
+	case FormatMarkdown:
+		return r.writeMarkdown(w)
+	}
+	return fmt.Errorf("unknown output format '%s' (valid: %s)", format, strings.Join(Formats, ", "))
+}
+
+type jsonCheck struct {
+	ID          string    `json:"id"`
+	Severity    Severity  `json:"severity"`
+	Description string    `json:"description"`
+	Passed      bool      `json:"passed"`
+	Findings    []Finding `json:"findings"`
+}
+
+type jsonReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Namespace   string    `json:"namespace,omitempty"`
+	Summary     struct {
+		Checks          int            `json:"checks"`
+		Passed          int            `json:"passed"`
+		Suppressed      int            `json:"suppressed"`
+		Findings        map[string]int `json:"findings"`
+		HighestSeverity *Severity      `json:"highest_severity"`
+	} `json:"summary"`
+	Checks []jsonCheck `json:"checks"`
+}
+
+func (r Report) writeJSON(w io.Writer) error {
+	out := jsonReport{GeneratedAt: r.GeneratedAt, Namespace: r.Namespace, Checks: []jsonCheck{}}
+	out.Summary.Checks = len(r.Results)
+	out.Summary.Suppressed = r.Suppressed
+	out.Summary.Findings = map[string]int{}
+	for severity, count := range r.Counts() {
+		out.Summary.Findings[severity.String()] = count
+	}
+	if highest, ok := r.Highest(); ok {
+		out.Summary.HighestSeverity = &highest
+	}
+
+	for _, result := range r.Results {
+		findings := result.Findings
+		if findings == nil {
+			findings = []Finding{}
+		}
+		if len(findings) == 0 {
+			out.Summary.Passed++
+		}
+		out.Checks = append(out.Checks, jsonCheck{
+			ID:          result.Check.ID,
+			Severity:    result.Check.Severity,
+			Description: result.Check.Description,
+			Passed:      len(findings) == 0,
+			Findings:    findings,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit reports each check as a test case that fails when it has findings
+func (r Report) writeJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      "kcsi check",
+		Tests:     len(r.Results),
+		Timestamp: r.GeneratedAt.UTC().Format(time.RFC3339),
+	}
+
+	for _, result := range r.Results {
+		testCase := junitTestCase{Name: result.Check.ID, ClassName: "kcsi.check"}
+		if len(result.Findings) > 0 {
+			suite.Failures++
+			lines := make([]string, 0, len(result.Findings))
+			for _, finding := range result.Findings {
+				lines = append(lines, fmt.Sprintf("%s: %s", finding.Resource, finding.Message))
+			}
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d finding(s): %s", len(result.Findings), result.Check.Description),
+				Type:    result.Check.Severity.String(),
+				Text:    strings.Join(lines, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name           string      `json:"name"`
+			Version        string      `json:"version,omitempty"`
+			InformationURI string      `json:"informationUri"`
+			Rules          []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+// writeSARIF reports findings as SARIF results with the cluster object as logical location
+func (r Report) writeSARIF(w io.Writer) error {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver.Name = "kcsi"
+	run.Tool.Driver.Version = r.ToolVersion
+	run.Tool.Driver.InformationURI = "https://github.com/stanzinofree/kcsi"
+	run.Tool.Driver.Rules = []sarifRule{}
+
+	for _, result := range r.Results {
+		rule := sarifRule{ID: result.Check.ID, ShortDescription: sarifMessage{Text: result.Check.Description}}
+		rule.DefaultConfig.Level = sarifLevel(result.Check.Severity)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+
+		for _, finding := range result.Findings {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  finding.CheckID,
+				Level:   sarifLevel(finding.Severity),
+				Message: sarifMessage{Text: fmt.Sprintf("%s: %s", finding.Resource, finding.Message)},
+				Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+					Name:               finding.Resource.Name,
+					FullyQualifiedName: finding.Resource.String(),
+					Kind:               strings.ToLower(finding.Resource.Kind),
+				}}}},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}})
+}
+
+// writeMarkdown renders a summary table followed by the findings of each failed check
+func (r Report) writeMarkdown(w io.Writer) error {
+	var sb strings.Builder
+
+	scope := "all namespaces"
+	if r.Namespace != "" {
+		scope = "namespace `" + r.Namespace + "`"
+	}
+	sb.WriteString("# kcsi check report\n\n")
+	sb.WriteString(fmt.Sprintf("Generated %s for %s.\n\n", r.GeneratedAt.UTC().Format(time.RFC3339), scope))
+	if r.Suppressed > 0 {
+		sb.WriteString(fmt.Sprintf("%d finding(s) suppressed by the baseline.\n\n", r.Suppressed))
+	}
+
+	sb.WriteString("| Check | Severity | Result |\n")
+	sb.WriteString("|-------|----------|--------|\n")
+	for _, result := range r.Results {
+		status := "✅ passed"
+		if len(result.Findings) > 0 {
+			status = fmt.Sprintf("❌ %d finding(s)", len(result.Findings))
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", result.Check.ID, result.Check.Severity, status))
+	}
+
+	for _, result := range r.Results {
+		if len(result.Findings) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s (%s)\n\n%s\n\n", result.Check.ID, result.Check.Severity, result.Check.Description))
+		sb.WriteString("| Resource | Message |\n")
+		sb.WriteString("|----------|---------|\n")
+		for _, finding := range result.Findings {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s |\n", finding.Resource, strings.ReplaceAll(finding.Message, "|", "\\|")))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,160 @@
+package checks
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleReport() Report {
+	nodes := Check{ID: "node-conditions", Severity: SeverityError, Description: "Nodes not ready"}
+	pvcs := Check{ID: "unbound-pvcs", Severity: SeverityWarning, Description: "Unbound PVCs"}
+	tags := Check{ID: "latest-image-tag", Severity: SeverityWarning, Description: "Latest tags"}
+
+	return Report{
+		GeneratedAt: time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC),
+		ToolVersion: "1.0.0",
+		Results: []Result{
+			{Check: nodes, Findings: []Finding{{CheckID: nodes.ID, Severity: SeverityError, Resource: ResourceRef{Kind: "Node", Name: "node-2"}, Message: "node is not ready"}}},
+			{Check: pvcs, Findings: []Finding{{CheckID: pvcs.ID, Severity: SeverityWarning, Resource: ResourceRef{Kind: "PersistentVolumeClaim", Namespace: "db", Name: "data-1"}, Message: "claim is Pending"}}},
+			{Check: tags},
+		},
+	}
+}
+
+func TestReportHighestAndCounts(t *testing.T) {
+	report := sampleReport()
+
+	highest, ok := report.Highest()
+	if !ok || highest != SeverityError {
+		t.Errorf("Expected highest severity error, got %s (%v)", highest, ok)
+	}
+
+	counts := report.Counts()
+	if counts[SeverityError] != 1 || counts[SeverityWarning] != 1 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+
+	if _, ok := (Report{}).Highest(); ok {
+		t.Error("Expected no highest severity for an empty report")
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().Write(&buf, FormatJSON); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var decoded struct {
+		Summary struct {
+			Checks          int            `json:"checks"`
+			Passed          int            `json:"passed"`
+			Findings        map[string]int `json:"findings"`
+			HighestSeverity string         `json:"highest_severity"`
+		} `json:"summary"`
+		Checks []struct {
+			ID       string `json:"id"`
+			Passed   bool   `json:"passed"`
+			Findings []struct {
+				Severity string `json:"severity"`
+				Resource struct {
+					Kind      string `json:"kind"`
+					Namespace string `json:"namespace"`
+					Name      string `json:"name"`
+				} `json:"resource"`
+			} `json:"findings"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	if decoded.Summary.Checks != 3 || decoded.Summary.Passed != 1 || decoded.Summary.HighestSeverity != "error" {
+		t.Errorf("Unexpected summary: %+v", decoded.Summary)
+	}
+	pvc := decoded.Checks[1].Findings[0]
+	if pvc.Severity != "warning" || pvc.Resource.Namespace != "db" || pvc.Resource.Name != "data-1" {
+		t.Errorf("Unexpected finding: %+v", pvc)
+	}
+	if !decoded.Checks[2].Passed || decoded.Checks[2].Findings == nil {
+		t.Errorf("Expected passed check with empty findings list, got %+v", decoded.Checks[2])
+	}
+}
+
+func TestReportJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().Write(&buf, FormatJUnit); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("Invalid XML: %v", err)
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 2 {
+		t.Errorf("Expected 3 tests and 2 failures, got %d/%d", suite.Tests, suite.Failures)
+	}
+	if suite.Cases[0].Failure == nil || suite.Cases[0].Failure.Type != "error" || !strings.Contains(suite.Cases[0].Failure.Text, "Node/node-2") {
+		t.Errorf("Unexpected failure: %+v", suite.Cases[0].Failure)
+	}
+	if suite.Cases[2].Failure != nil {
+		t.Error("Expected passing check without failure")
+	}
+}
+
+func TestReportSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().Write(&buf, FormatSARIF); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Invalid SARIF: %v", err)
+	}
+	run := log.Runs[0]
+	if log.Version != "2.1.0" || len(run.Tool.Driver.Rules) != 3 || len(run.Results) != 2 {
+		t.Fatalf("Unexpected SARIF content: %s", buf.String())
+	}
+	result := run.Results[1]
+	if result.RuleID != "unbound-pvcs" || result.Level != "warning" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if location := result.Locations[0].LogicalLocations[0]; location.FullyQualifiedName != "db/PersistentVolumeClaim/data-1" {
+		t.Errorf("Unexpected location: %+v", location)
+	}
+}
+
+func TestReportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleReport().Write(&buf, FormatMarkdown); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, expected := range []string{"| `node-conditions` | error | ❌ 1 finding(s) |", "| `latest-image-tag` | warning | ✅ passed |", "## unbound-pvcs (warning)", "| `db/PersistentVolumeClaim/data-1` | claim is Pending |"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected markdown to contain %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestReportUnknownFormat(t *testing.T) {
+	if err := sampleReport().Write(&bytes.Buffer{}, "yaml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := ParseSeverity("Warning"); err != nil || s != SeverityWarning {
+		t.Errorf("Expected warning, got %s (%v)", s, err)
+	}
+	if _, err := ParseSeverity("critical"); err == nil {
+		t.Error("Expected error for unknown severity")
+	}
+}
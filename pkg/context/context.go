@@ -22,6 +22,7 @@ type Context struct {
 	KubeconfigPath   string `yaml:"kubeconfig_path"`
 	Description      string `yaml:"description,omitempty"`
 	DefaultNamespace string `yaml:"default_namespace,omitempty"`
+	Protected        bool   `yaml:"protected,omitempty"`
 }
 
 // Config represents the contexts configuration file
@@ -375,3 +376,37 @@ func GetCurrentDefaultNamespace() (string, error) {
 
 	return ctx.DefaultNamespace, nil
 }
+
+// SetProtected marks a context as protected (or unprotected).
+// Destructive commands require explicit confirmation flags on protected contexts.
+func SetProtected(contextName string, protected bool) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i, ctx := range config.Contexts {
+		if ctx.Name == contextName {
+			config.Contexts[i].Protected = protected
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("context '%s' not found", contextName)
+	}
+
+	return SaveConfig(config)
+}
+
+// IsCurrentContextProtected reports whether the current active context is protected
+func IsCurrentContextProtected() bool {
+	ctx, err := GetCurrentContext()
+	if err != nil {
+		return false
+	}
+
+	return ctx.Protected
+}
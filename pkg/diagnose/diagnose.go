@@ -0,0 +1,311 @@
+// Package diagnose derives likely causes of failing pods from their status,
+// events and previous container logs.
+package diagnose
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// Cause is a likely explanation of why a pod or one of its containers is failing
+type Cause struct {
+	Container string
+	Summary   string
+	Hint      string
+}
+
+// Input is everything gathered about a pod for the analysis
+type Input struct {
+	Pod    kubernetes.Pod
+	Events []kubernetes.Event
+	// PreviousLogs holds the log tail of the previous instance of each restarted container
+	PreviousLogs map[string][]string
+}
+
+// Probe defaults applied by Kubernetes when a field is not set
+const (
+	defaultProbeTimeout   = 1
+	defaultProbePeriod    = 10
+	defaultProbeThreshold = 3
+)
+
+// errorLinePattern matches log lines that likely explain a crash
+var errorLinePattern = regexp.MustCompile(`(?i)(panic|fatal|error|exception|refused|denied|no such file|not found|killed)`)
+
+// Analyze returns the likely causes of a pod's failures, most relevant first
+func Analyze(in Input) []Cause {
+	var causes []Cause
+
+	pod := in.Pod
+	if pod.Status.Reason == "Evicted" {
+		causes = append(causes, Cause{
+			Summary: "Pod was evicted: " + pod.Status.Message,
+			Hint:    "The node ran short of resources; check node pressure conditions and the pod's requests",
+		})
+	}
+
+	if pod.Status.Phase == "Pending" {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == "PodScheduled" && cond.Status == "False" {
+				causes = append(causes, Cause{
+					Summary: "Pod cannot be scheduled: " + firstNonEmpty(cond.Message, lastEventMessage(in.Events, "", "FailedScheduling")),
+					Hint:    "Check node capacity against the pod's requests, taints and tolerations, node selectors and PVC bindings",
+				})
+			}
+		}
+	}
+
+	containers := map[string]kubernetes.Container{}
+	for _, c := range append(append([]kubernetes.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		containers[c.Name] = c
+	}
+
+	statuses := append(append([]kubernetes.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		causes = append(causes, analyzeContainer(in, containers[status.Name], status)...)
+	}
+
+	return causes
+}
+
+func analyzeContainer(in Input, container kubernetes.Container, status kubernetes.ContainerStatus) []Cause {
+	var causes []Cause
+	name := status.Name
+
+	if waiting := status.State.Waiting; waiting != nil {
+		switch waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+			message := firstNonEmpty(lastEventMessage(in.Events, name, "Failed"), waiting.Message)
+			causes = append(causes, Cause{
+				Container: name,
+				Summary:   fmt.Sprintf("Image %s cannot be pulled: %s", container.Image, message),
+				Hint:      imagePullHint(waiting.Reason, message),
+			})
+		case "CreateContainerConfigError":
+			causes = append(causes, Cause{
+				Container: name,
+				Summary:   "Container configuration is invalid: " + waiting.Message,
+				Hint:      "Create the missing ConfigMap or Secret, or fix the key referenced in env/envFrom/volumes",
+			})
+		case "CreateContainerError", "RunContainerError":
+			causes = append(causes, Cause{
+				Container: name,
+				Summary:   "Container could not be started: " + waiting.Message,
+				Hint:      "Check the command, volume mounts and security context of the container",
+			})
+		}
+	}
+
+	livenessFailures := probeFailures(in.Events, name, "Liveness")
+
+	if terminated := lastTermination(status); terminated != nil && (status.RestartCount > 0 || terminated.ExitCode != 0) {
+		causes = append(causes, analyzeTermination(in, container, status, livenessFailures)...)
+	} else if livenessFailures != "" {
+		causes = append(causes, livenessCause(container, livenessFailures))
+	}
+
+	if readiness := probeFailures(in.Events, name, "Readiness"); readiness != "" && !status.Ready {
+		causes = append(causes, Cause{
+			Container: name,
+			Summary:   "Readiness probe is failing: " + readiness,
+			Hint:      "The pod receives no traffic until the probe passes; check the probe path and port (" + DescribeProbe(container.ReadinessProbe) + ")",
+		})
+	}
+
+	return causes
+}
+
+// analyzeTermination explains the last termination of a container
+func analyzeTermination(in Input, container kubernetes.Container, status kubernetes.ContainerStatus, livenessFailures string) []Cause {
+	terminated := lastTermination(status)
+	name := status.Name
+	exit := fmt.Sprintf("exit code %d", terminated.ExitCode)
+
+	switch {
+	case terminated.Reason == "OOMKilled":
+		if limit, ok := container.Resources.Limits["memory"]; ok {
+			return []Cause{{
+				Container: name,
+				Summary:   fmt.Sprintf("Container was OOMKilled: it exceeded its memory limit of %s (%s)", limit, exit),
+				Hint:      "Raise the memory limit or reduce the application's memory usage (heap size, caches, concurrency)",
+			}}
+		}
+		return []Cause{{
+			Container: name,
+			Summary:   fmt.Sprintf("Container was OOMKilled without a memory limit (%s)", exit),
+			Hint:      "The node ran out of memory; set memory requests and limits so the pod is scheduled where it fits",
+		}}
+
+	case livenessFailures != "" && (terminated.ExitCode == 137 || terminated.ExitCode == 143):
+		return []Cause{livenessCause(container, livenessFailures)}
+
+	case terminated.ExitCode == 137:
+		return []Cause{{
+			Container: name,
+			Summary:   "Container was killed with SIGKILL (exit code 137)",
+			Hint:      "Usually a memory limit hit below the OOM reporting threshold or a failed graceful shutdown; compare usage with the limits",
+		}}
+
+	case terminated.ExitCode == 143:
+		return []Cause{{
+			Container: name,
+			Summary:   "Container was terminated with SIGTERM (exit code 143)",
+			Hint:      "Something asked the container to stop; check for preemption, node drains or a sidecar shutting it down",
+		}}
+
+	case terminated.ExitCode == 126 || terminated.ExitCode == 127:
+		return []Cause{{
+			Container: name,
+			Summary:   fmt.Sprintf("Container command not found or not executable (%s): %s", exit, terminated.Message),
+			Hint:      "Check that the command/entrypoint exists in the image and is executable",
+		}}
+
+	case terminated.ExitCode == 0:
+		if in.Pod.Spec.RestartPolicy == "" || in.Pod.Spec.RestartPolicy == "Always" {
+			return []Cause{{
+				Container: name,
+				Summary:   "Main process exits successfully (exit code 0) and is restarted by restartPolicy Always",
+				Hint:      "A long-running process is expected; use a Job for one-off tasks or keep the process in the foreground",
+			}}
+		}
+		return nil
+	}
+
+	cause := Cause{
+		Container: name,
+		Summary:   fmt.Sprintf("Application exited with an error (%s, reason %s)", exit, terminated.Reason),
+		Hint:      "Check the previous container logs for the error that made the application stop",
+	}
+	if line := LastErrorLine(in.PreviousLogs[name]); line != "" {
+		cause.Hint = "Last error in the previous logs: " + line
+	}
+	return []Cause{cause}
+}
+
+// lastTermination returns the current terminated state of a container, or
+// the one of its previous instance
+func lastTermination(status kubernetes.ContainerStatus) *kubernetes.TerminatedState {
+	if status.State.Terminated != nil {
+		return status.State.Terminated
+	}
+	return status.LastState.Terminated
+}
+
+func livenessCause(container kubernetes.Container, failures string) Cause {
+	hint := "Check the probe endpoint and raise timeoutSeconds or failureThreshold if the application is slow to respond"
+	if probe := container.LivenessProbe; probe != nil && probe.InitialDelaySeconds < 10 && container.StartupProbe == nil {
+		hint = "The probe starts almost immediately; add a startupProbe or raise initialDelaySeconds for slow-starting applications"
+	}
+	return Cause{
+		Container: container.Name,
+		Summary:   "Container is restarted after failing its liveness probe: " + failures,
+		Hint:      hint + " (" + DescribeProbe(container.LivenessProbe) + ")",
+	}
+}
+
+// imagePullHint explains the most common image pull failures
+func imagePullHint(reason, message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case reason == "InvalidImageName":
+		return "The image reference is malformed; check the repository, name and tag"
+	case strings.Contains(lower, "not found") || strings.Contains(lower, "manifest unknown"):
+		return "The image or tag does not exist; check the name and tag"
+	case strings.Contains(lower, "unauthorized") || strings.Contains(lower, "denied") || strings.Contains(lower, "authentication required"):
+		return "The registry rejected the credentials; check imagePullSecrets on the pod or service account"
+	case strings.Contains(lower, "timeout") || strings.Contains(lower, "no such host") || strings.Contains(lower, "connection refused"):
+		return "The registry is unreachable from the node; check DNS, proxies and network policies"
+	case strings.Contains(lower, "toomanyrequests") || strings.Contains(lower, "rate limit"):
+		return "The registry is rate limiting pulls; authenticate or use a mirror"
+	}
+	return "Check the image name, tag and registry credentials"
+}
+
+// probeFailures returns the latest failure message of a probe kind
+// (Liveness, Readiness, Startup) for a container
+func probeFailures(events []kubernetes.Event, container, kind string) string {
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Reason != "Unhealthy" || !eventMatchesContainer(event, container) {
+			continue
+		}
+		if strings.HasPrefix(event.Message, kind+" probe failed") {
+			message := strings.TrimSpace(strings.TrimPrefix(event.Message, kind+" probe failed:"))
+			if event.Count > 1 {
+				message = fmt.Sprintf("%s (x%d)", message, event.Count)
+			}
+			return message
+		}
+	}
+	return ""
+}
+
+// lastEventMessage returns the message of the latest event with a reason,
+// optionally restricted to a container
+func lastEventMessage(events []kubernetes.Event, container, reason string) string {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Reason == reason && (container == "" || eventMatchesContainer(events[i], container)) {
+			return events[i].Message
+		}
+	}
+	return ""
+}
+
+// eventMatchesContainer reports whether an event refers to a container
+// (fieldPath "spec.containers{name}"); pod level events match every container
+func eventMatchesContainer(event kubernetes.Event, container string) bool {
+	fieldPath := event.InvolvedObject.FieldPath
+	return fieldPath == "" || strings.HasSuffix(fieldPath, "{"+container+"}")
+}
+
+// LastErrorLine returns the last log line that looks like an error
+func LastErrorLine(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" && errorLinePattern.MatchString(line) {
+			return line
+		}
+	}
+	return ""
+}
+
+// DescribeProbe renders a probe like kubectl describe does
+func DescribeProbe(probe *kubernetes.Probe) string {
+	if probe == nil {
+		return "none"
+	}
+
+	action := "unknown"
+	switch {
+	case probe.HTTPGet != nil:
+		action = fmt.Sprintf("http-get :%v%s", probe.HTTPGet.Port, probe.HTTPGet.Path)
+	case probe.TCPSocket != nil:
+		action = fmt.Sprintf("tcp-socket :%v", probe.TCPSocket.Port)
+	case probe.Exec != nil:
+		action = "exec [" + strings.Join(probe.Exec.Command, " ") + "]"
+	}
+
+	return fmt.Sprintf("%s delay=%ds timeout=%ds period=%ds #failure=%d", action,
+		probe.InitialDelaySeconds,
+		orDefault(probe.TimeoutSeconds, defaultProbeTimeout),
+		orDefault(probe.PeriodSeconds, defaultProbePeriod),
+		orDefault(probe.FailureThreshold, defaultProbeThreshold))
+}
+
+func orDefault(value, def int) int {
+	if value == 0 {
+		return def
+	}
+	return value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+package diagnose
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+func TestAnalyzeOOMKilled(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{
+		"spec": {"containers": [{"name": "app", "resources": {"limits": {"memory": "256Mi"}}}]},
+		"status": {
+			"phase": "Running",
+			"containerStatuses": [{
+				"name": "app", "restartCount": 4,
+				"state": {"waiting": {"reason": "CrashLoopBackOff"}},
+				"lastState": {"terminated": {"exitCode": 137, "reason": "OOMKilled"}}
+			}]
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+
+	causes := Analyze(Input{Pod: pod})
+	if len(causes) != 1 {
+		t.Fatalf("Expected 1 cause, got %d: %+v", len(causes), causes)
+	}
+	if causes[0].Container != "app" || !strings.Contains(causes[0].Summary, "memory limit of 256Mi") {
+		t.Errorf("Unexpected cause: %+v", causes[0])
+	}
+}
+
+func TestAnalyzeLivenessKill(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{
+		"spec": {"containers": [{"name": "app", "livenessProbe": {"httpGet": {"path": "/healthz", "port": 8080}}}]},
+		"status": {
+			"containerStatuses": [{
+				"name": "app", "restartCount": 2,
+				"lastState": {"terminated": {"exitCode": 137, "reason": "Error"}}
+			}]
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+	events := []kubernetes.Event{{
+		Reason:  "Unhealthy",
+		Message: "Liveness probe failed: HTTP probe failed with statuscode: 500",
+		Count:   6,
+	}}
+	events[0].InvolvedObject.FieldPath = "spec.containers{app}"
+
+	causes := Analyze(Input{Pod: pod, Events: events})
+	if len(causes) != 1 {
+		t.Fatalf("Expected 1 cause, got %d: %+v", len(causes), causes)
+	}
+	if !strings.Contains(causes[0].Summary, "liveness probe") || !strings.Contains(causes[0].Summary, "(x6)") {
+		t.Errorf("Unexpected summary: %s", causes[0].Summary)
+	}
+	if !strings.Contains(causes[0].Hint, "startupProbe") || !strings.Contains(causes[0].Hint, "http-get :8080/healthz") {
+		t.Errorf("Unexpected hint: %s", causes[0].Hint)
+	}
+}
+
+func TestAnalyzeApplicationError(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{
+		"spec": {"containers": [{"name": "app"}]},
+		"status": {
+			"containerStatuses": [{
+				"name": "app", "restartCount": 7,
+				"lastState": {"terminated": {"exitCode": 1, "reason": "Error"}}
+			}]
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+	logs := map[string][]string{"app": {
+		"starting server",
+		"ERROR: dial tcp 10.0.0.5:5432: connect: connection refused",
+		"shutting down",
+	}}
+
+	causes := Analyze(Input{Pod: pod, PreviousLogs: logs})
+	if len(causes) != 1 {
+		t.Fatalf("Expected 1 cause, got %d", len(causes))
+	}
+	if !strings.Contains(causes[0].Summary, "exit code 1") || !strings.Contains(causes[0].Hint, "connection refused") {
+		t.Errorf("Unexpected cause: %+v", causes[0])
+	}
+}
+
+func TestAnalyzeImagePull(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{
+		"spec": {"containers": [{"name": "app", "image": "registry.example.com/app:v9"}]},
+		"status": {
+			"phase": "Pending",
+			"containerStatuses": [{"name": "app", "state": {"waiting": {"reason": "ImagePullBackOff"}}}]
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+	events := []kubernetes.Event{{
+		Reason:  "Failed",
+		Message: "Failed to pull image: unauthorized: authentication required",
+	}}
+
+	causes := Analyze(Input{Pod: pod, Events: events})
+	if len(causes) != 1 {
+		t.Fatalf("Expected 1 cause, got %d", len(causes))
+	}
+	if !strings.Contains(causes[0].Summary, "registry.example.com/app:v9") || !strings.Contains(causes[0].Hint, "imagePullSecrets") {
+		t.Errorf("Unexpected cause: %+v", causes[0])
+	}
+}
+
+func TestAnalyzeUnschedulable(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{
+		"status": {
+			"phase": "Pending",
+			"conditions": [{"type": "PodScheduled", "status": "False", "reason": "Unschedulable",
+				"message": "0/3 nodes are available: 3 Insufficient memory."}]
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+
+	causes := Analyze(Input{Pod: pod})
+	if len(causes) != 1 || !strings.Contains(causes[0].Summary, "Insufficient memory") {
+		t.Errorf("Unexpected causes: %+v", causes)
+	}
+}
+
+func TestAnalyzeHealthyPod(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{
+		"spec": {"containers": [{"name": "app"}]},
+		"status": {"phase": "Running", "containerStatuses": [{"name": "app", "ready": true, "state": {"running": {}}}]}
+	}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+
+	if causes := Analyze(Input{Pod: pod}); len(causes) != 0 {
+		t.Errorf("Expected no causes, got %+v", causes)
+	}
+}
+
+func TestDescribeProbe(t *testing.T) {
+	if got := DescribeProbe(nil); got != "none" {
+		t.Errorf("Expected none, got %s", got)
+	}
+
+	probe := &kubernetes.Probe{InitialDelaySeconds: 5, PeriodSeconds: 20}
+	probe.Exec = &struct {
+		Command []string `json:"command"`
+	}{Command: []string{"cat", "/tmp/healthy"}}
+
+	expected := "exec [cat /tmp/healthy] delay=5s timeout=1s period=20s #failure=3"
+	if got := DescribeProbe(probe); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
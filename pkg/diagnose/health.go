@@ -0,0 +1,145 @@
+package diagnose
+
+import (
+	"fmt"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// Category classifies a pod health problem
+type Category string
+
+// Pod health problem categories, in the order they are reported
+const (
+	CategoryCrashLooping         Category = "crashlooping"
+	CategoryImagePull            Category = "image-pull"
+	CategoryPendingUnschedulable Category = "pending-unschedulable"
+	CategoryEvicted              Category = "evicted"
+	CategoryFailed               Category = "failed"
+	CategoryNotReady             Category = "not-ready"
+	CategoryHighRestarts         Category = "high-restarts"
+)
+
+// Categories lists every pod health category in reporting order
+var Categories = []Category{
+	CategoryCrashLooping,
+	CategoryImagePull,
+	CategoryPendingUnschedulable,
+	CategoryEvicted,
+	CategoryFailed,
+	CategoryNotReady,
+	CategoryHighRestarts,
+}
+
+// PodProblem is a single health problem found on a pod
+type PodProblem struct {
+	Category  Category
+	Container string
+	Reason    string
+	Message   string
+}
+
+// imagePullReasons are the waiting reasons of containers whose image cannot be pulled
+var imagePullReasons = map[string]bool{
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+	"InvalidImageName": true,
+}
+
+// PodProblems classifies the health problems of a pod. Containers restarted
+// at least restartThreshold times are reported as high-restarts; a threshold
+// of 0 disables that category.
+func PodProblems(pod kubernetes.Pod, restartThreshold int) []PodProblem {
+	// Completed pods and pods being deleted are expected to be not ready
+	if pod.Status.Phase == "Succeeded" || pod.Metadata.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if pod.Status.Phase == "Failed" {
+		category := CategoryFailed
+		if pod.Status.Reason == "Evicted" {
+			category = CategoryEvicted
+		}
+		return []PodProblem{{Category: category, Reason: pod.Status.Reason, Message: pod.Status.Message}}
+	}
+
+	var problems []PodProblem
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == "PodScheduled" && cond.Status == "False" {
+			problems = append(problems, PodProblem{Category: CategoryPendingUnschedulable, Reason: cond.Reason, Message: cond.Message})
+		}
+	}
+
+	statuses := append(append([]kubernetes.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch {
+			case waiting.Reason == "CrashLoopBackOff":
+				problems = append(problems, PodProblem{Category: CategoryCrashLooping, Container: status.Name, Reason: waiting.Reason, Message: lastTerminationSummary(status)})
+			case imagePullReasons[waiting.Reason]:
+				problems = append(problems, PodProblem{Category: CategoryImagePull, Container: status.Name, Reason: waiting.Reason, Message: waiting.Message})
+			}
+		}
+
+		if restartThreshold > 0 && status.RestartCount >= restartThreshold {
+			problems = append(problems, PodProblem{
+				Category:  CategoryHighRestarts,
+				Container: status.Name,
+				Reason:    fmt.Sprintf("%d restarts", status.RestartCount),
+				Message:   lastTerminationSummary(status),
+			})
+		}
+	}
+
+	// Pods already explained by a more specific category are not repeated as not-ready
+	if !pod.IsReady() && !explained(problems) {
+		problems = append(problems, notReadyProblem(pod))
+	}
+
+	return problems
+}
+
+// notReadyProblem explains why a scheduled pod is not ready
+func notReadyProblem(pod kubernetes.Pod) PodProblem {
+	problem := PodProblem{Category: CategoryNotReady, Reason: pod.Status.Phase}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			continue
+		}
+		problem.Container = status.Name
+		if status.State.Waiting != nil {
+			problem.Reason = status.State.Waiting.Reason
+			problem.Message = status.State.Waiting.Message
+		} else {
+			problem.Reason = "ContainerNotReady"
+		}
+		return problem
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == "Ready" && cond.Reason != "" {
+			problem.Reason = cond.Reason
+			problem.Message = cond.Message
+		}
+	}
+	return problem
+}
+
+// explained reports whether problems contain a category more specific than high-restarts
+func explained(problems []PodProblem) bool {
+	for _, p := range problems {
+		if p.Category != CategoryHighRestarts {
+			return true
+		}
+	}
+	return false
+}
+
+// lastTerminationSummary describes the last termination of a container
+func lastTerminationSummary(status kubernetes.ContainerStatus) string {
+	terminated := lastTermination(status)
+	if terminated == nil {
+		return ""
+	}
+	return fmt.Sprintf("last exit: %s (exit code %d)", terminated.Reason, terminated.ExitCode)
+}
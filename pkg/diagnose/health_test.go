@@ -0,0 +1,95 @@
+package diagnose
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+func categories(problems []PodProblem) []Category {
+	var result []Category
+	for _, p := range problems {
+		result = append(result, p.Category)
+	}
+	return result
+}
+
+func TestPodProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      string
+		expected []Category
+	}{
+		{
+			name:     "healthy",
+			pod:      `{"status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}], "containerStatuses": [{"name": "app", "ready": true}]}}`,
+			expected: nil,
+		},
+		{
+			name:     "completed",
+			pod:      `{"status": {"phase": "Succeeded"}}`,
+			expected: nil,
+		},
+		{
+			name:     "running but not ready",
+			pod:      `{"metadata": {"name": "running-completed-job"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "False"}], "containerStatuses": [{"name": "app", "ready": false, "state": {"running": {}}}]}}`,
+			expected: []Category{CategoryNotReady},
+		},
+		{
+			name: "crashlooping with high restarts",
+			pod: `{"status": {"phase": "Running", "containerStatuses": [{"name": "app", "restartCount": 9,
+				"state": {"waiting": {"reason": "CrashLoopBackOff"}}, "lastState": {"terminated": {"exitCode": 1, "reason": "Error"}}}]}}`,
+			expected: []Category{CategoryCrashLooping, CategoryHighRestarts},
+		},
+		{
+			name:     "image pull",
+			pod:      `{"status": {"phase": "Pending", "containerStatuses": [{"name": "app", "state": {"waiting": {"reason": "ImagePullBackOff"}}}]}}`,
+			expected: []Category{CategoryImagePull},
+		},
+		{
+			name:     "unschedulable",
+			pod:      `{"status": {"phase": "Pending", "conditions": [{"type": "PodScheduled", "status": "False", "reason": "Unschedulable"}]}}`,
+			expected: []Category{CategoryPendingUnschedulable},
+		},
+		{
+			name:     "evicted",
+			pod:      `{"status": {"phase": "Failed", "reason": "Evicted", "message": "The node was low on resource: memory."}}`,
+			expected: []Category{CategoryEvicted},
+		},
+		{
+			name:     "ready with restarts",
+			pod:      `{"status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}], "containerStatuses": [{"name": "app", "ready": true, "restartCount": 5}]}}`,
+			expected: []Category{CategoryHighRestarts},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pod kubernetes.Pod
+			if err := json.Unmarshal([]byte(tt.pod), &pod); err != nil {
+				t.Fatalf("Failed to decode pod fixture: %v", err)
+			}
+			got := categories(PodProblems(pod, 5))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected categories %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected categories %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPodProblemsRestartThresholdDisabled(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{"status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}], "containerStatuses": [{"name": "app", "ready": true, "restartCount": 50}]}}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod fixture: %v", err)
+	}
+	if problems := PodProblems(pod, 0); len(problems) != 0 {
+		t.Errorf("Expected no problems with threshold 0, got %+v", problems)
+	}
+}
@@ -0,0 +1,203 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeType describes how a single field differs between two objects
+type ChangeType string
+
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Modified ChangeType = "modified"
+)
+
+// Change represents a field-level difference between two objects
+type Change struct {
+	Path string
+	Type ChangeType
+	Old  interface{}
+	New  interface{}
+}
+
+// noiseMetadataFields are server-managed metadata fields that change on every write
+var noiseMetadataFields = []string{
+	"resourceVersion",
+	"generation",
+	"managedFields",
+	"uid",
+	"creationTimestamp",
+	"selfLink",
+}
+
+// noiseAnnotations are annotations maintained by tooling rather than by users
+var noiseAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+// LoadObject parses a YAML or JSON document into a generic object.
+// Values are normalized through JSON so that objects loaded from YAML and
+// JSON compare equal.
+func LoadObject(data []byte) (map[string]interface{}, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse object: %w", err)
+	}
+
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize object: %w", err)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(normalized, &obj); err != nil {
+		return nil, fmt.Errorf("failed to normalize object: %w", err)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("document is empty")
+	}
+
+	return obj, nil
+}
+
+// StripNoise returns a copy of obj without status and server-managed metadata
+func StripNoise(obj map[string]interface{}) map[string]interface{} {
+	clean := deepCopy(obj).(map[string]interface{})
+	delete(clean, "status")
+
+	metadata, ok := clean["metadata"].(map[string]interface{})
+	if !ok {
+		return clean
+	}
+
+	for _, field := range noiseMetadataFields {
+		delete(metadata, field)
+	}
+
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		for _, key := range noiseAnnotations {
+			delete(annotations, key)
+		}
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+
+	return clean
+}
+
+// Objects compares two generic objects and returns the changed fields sorted by path
+func Objects(oldObj, newObj map[string]interface{}) []Change {
+	var changes []Change
+	compare("", oldObj, newObj, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func compare(path string, oldVal, newVal interface{}, changes *[]Change) {
+	oldMap, oldIsMap := oldVal.(map[string]interface{})
+	newMap, newIsMap := newVal.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		compareMaps(path, oldMap, newMap, changes)
+		return
+	}
+
+	oldList, oldIsList := oldVal.([]interface{})
+	newList, newIsList := newVal.([]interface{})
+	if oldIsList && newIsList {
+		compareLists(path, oldList, newList, changes)
+		return
+	}
+
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*changes = append(*changes, Change{Path: path, Type: Modified, Old: oldVal, New: newVal})
+	}
+}
+
+func compareMaps(path string, oldMap, newMap map[string]interface{}, changes *[]Change) {
+	for key, oldVal := range oldMap {
+		childPath := joinPath(path, key)
+		newVal, ok := newMap[key]
+		if !ok {
+			*changes = append(*changes, Change{Path: childPath, Type: Removed, Old: oldVal})
+			continue
+		}
+		compare(childPath, oldVal, newVal, changes)
+	}
+
+	for key, newVal := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			*changes = append(*changes, Change{Path: joinPath(path, key), Type: Added, New: newVal})
+		}
+	}
+}
+
+func compareLists(path string, oldList, newList []interface{}, changes *[]Change) {
+	for i := 0; i < len(oldList) || i < len(newList); i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(newList):
+			*changes = append(*changes, Change{Path: childPath, Type: Removed, Old: oldList[i]})
+		case i >= len(oldList):
+			*changes = append(*changes, Change{Path: childPath, Type: Added, New: newList[i]})
+		default:
+			compare(childPath, oldList[i], newList[i], changes)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		key = fmt.Sprintf("[%q]", key)
+		return path + key
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// FormatValue renders a field value on a single line for display
+func FormatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return fmt.Sprintf("%q", val)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(data)
+	}
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = deepCopy(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = deepCopy(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestLoadObjectYAMLAndJSONEqual(t *testing.T) {
+	fromYAML, err := LoadObject([]byte("spec:\n  replicas: 3\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromJSON, err := LoadObject([]byte(`{"spec":{"replicas":3}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if changes := Objects(fromYAML, fromJSON); len(changes) != 0 {
+		t.Errorf("Expected no changes between YAML and JSON documents, got %v", changes)
+	}
+}
+
+func TestLoadObjectEmpty(t *testing.T) {
+	if _, err := LoadObject([]byte("")); err == nil {
+		t.Error("Expected error for empty document, got nil")
+	}
+}
+
+func TestStripNoise(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"resourceVersion": "123",
+			"generation":      float64(4),
+			"managedFields":   []interface{}{"x"},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+		},
+		"status": map[string]interface{}{"replicas": float64(1)},
+	}
+
+	clean := StripNoise(obj)
+	metadata := clean["metadata"].(map[string]interface{})
+
+	for _, field := range []string{"resourceVersion", "generation", "managedFields", "annotations"} {
+		if _, ok := metadata[field]; ok {
+			t.Errorf("Expected %s to be stripped", field)
+		}
+	}
+	if _, ok := clean["status"]; ok {
+		t.Error("Expected status to be stripped")
+	}
+	if metadata["name"] != "web" {
+		t.Errorf("Expected name to be kept, got %v", metadata["name"])
+	}
+
+	// The original object must not be modified
+	if _, ok := obj["metadata"].(map[string]interface{})["resourceVersion"]; !ok {
+		t.Error("StripNoise modified the original object")
+	}
+}
+
+func TestObjects(t *testing.T) {
+	oldObj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"paused":   true,
+			"containers": []interface{}{
+				map[string]interface{}{"image": "web:1"},
+			},
+		},
+	}
+	newObj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(3),
+			"containers": []interface{}{
+				map[string]interface{}{"image": "web:2"},
+				map[string]interface{}{"image": "sidecar:1"},
+			},
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+		},
+	}
+
+	changes := Objects(oldObj, newObj)
+
+	expected := []struct {
+		path       string
+		changeType ChangeType
+	}{
+		{"spec.containers[0].image", Modified},
+		{"spec.containers[1]", Added},
+		{"spec.labels", Added},
+		{"spec.paused", Removed},
+		{"spec.replicas", Modified},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+	for i, exp := range expected {
+		if changes[i].Path != exp.path || changes[i].Type != exp.changeType {
+			t.Errorf("Change %d: expected %s (%s), got %s (%s)", i, exp.path, exp.changeType, changes[i].Path, changes[i].Type)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "<none>"},
+		{"web", `"web"`},
+		{float64(3), "3"},
+		{map[string]interface{}{"a": "b"}, `{"a":"b"}`},
+	}
+
+	for _, tt := range tests {
+		if got := FormatValue(tt.value); got != tt.expected {
+			t.Errorf("FormatValue(%v) = %s, expected %s", tt.value, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package drain plans node drains: which pods are evicted, which are left
+// alone, which need explicit confirmation and which PodDisruptionBudgets
+// currently block an eviction. It also keeps the state file that lets an
+// interrupted drain resume.
+package drain
+
+import (
+	"sort"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// mirrorPodAnnotation marks static pods managed by the kubelet, which
+// cannot be evicted through the API
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// Options mirror the kubectl drain flags that allow evicting risky pods
+type Options struct {
+	// Force evicts pods that are not managed by a controller; they are not recreated
+	Force bool
+	// DeleteEmptyDirData evicts pods using emptyDir volumes; their data is lost
+	DeleteEmptyDirData bool
+}
+
+// Excluded is a pod the drain does not evict, with the reason
+type Excluded struct {
+	Pod    kubernetes.Pod
+	Reason string
+}
+
+// Blocker is a PodDisruptionBudget that allows no disruption right now,
+// with the pods to evict that it covers
+type Blocker struct {
+	PDB  kubernetes.PodDisruptionBudget
+	Pods []string
+}
+
+// Plan is the outcome of planning a drain
+type Plan struct {
+	// Evict are the pods to evict, sorted by namespace and name
+	Evict []kubernetes.Pod
+	// Ignored are pods left on the node: DaemonSet pods, mirror pods,
+	// finished pods and pods already terminating
+	Ignored []Excluded
+	// Refused are pods that need Force or DeleteEmptyDirData; the drain
+	// must not start while there are any
+	Refused []Excluded
+	// Blockers are PDBs that will hold evictions until they allow disruptions
+	Blockers []Blocker
+}
+
+// PodKey identifies a pod as namespace/name
+func PodKey(pod kubernetes.Pod) string {
+	return pod.Metadata.Namespace + "/" + pod.Metadata.Name
+}
+
+// NewPlan classifies the pods of a node and finds the blocking PDBs
+func NewPlan(pods []kubernetes.Pod, pdbs []kubernetes.PodDisruptionBudget, opts Options) Plan {
+	sorted := append([]kubernetes.Pod{}, pods...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return PodKey(sorted[i]) < PodKey(sorted[j])
+	})
+
+	var plan Plan
+	for _, pod := range sorted {
+		if reason := ignoreReason(pod); reason != "" {
+			plan.Ignored = append(plan.Ignored, Excluded{Pod: pod, Reason: reason})
+			continue
+		}
+		if reason := refuseReason(pod, opts); reason != "" {
+			plan.Refused = append(plan.Refused, Excluded{Pod: pod, Reason: reason})
+			continue
+		}
+		plan.Evict = append(plan.Evict, pod)
+	}
+
+	for _, pdb := range pdbs {
+		if pdb.Status.DisruptionsAllowed > 0 {
+			continue
+		}
+		var covered []string
+		for _, pod := range plan.Evict {
+			if pod.Metadata.Namespace == pdb.Metadata.Namespace && pdb.Spec.Selector.Matches(pod.Metadata.Labels) {
+				covered = append(covered, PodKey(pod))
+			}
+		}
+		if len(covered) > 0 {
+			plan.Blockers = append(plan.Blockers, Blocker{PDB: pdb, Pods: covered})
+		}
+	}
+
+	return plan
+}
+
+// ignoreReason returns why a pod stays on the node, or "" when it is drained
+func ignoreReason(pod kubernetes.Pod) string {
+	if _, ok := pod.Metadata.Annotations[mirrorPodAnnotation]; ok {
+		return "static pod"
+	}
+	if controllerKind(pod) == "DaemonSet" {
+		return "DaemonSet pod"
+	}
+	if pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+		return "finished"
+	}
+	if pod.Metadata.DeletionTimestamp != nil {
+		return "already terminating"
+	}
+	return ""
+}
+
+// refuseReason returns why a pod needs an explicit option, or "" when it can be evicted
+func refuseReason(pod kubernetes.Pod, opts Options) string {
+	if controllerKind(pod) == "" && !opts.Force {
+		return "not managed by a controller, it will not be recreated (use --force)"
+	}
+	if !opts.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				return "uses emptyDir volume " + volume.Name + ", its data will be lost (use --delete-emptydir-data)"
+			}
+		}
+	}
+	return ""
+}
+
+// controllerKind returns the kind of the controller owning a pod, or ""
+func controllerKind(pod kubernetes.Pod) string {
+	for _, owner := range pod.Metadata.OwnerReferences {
+		if owner.Controller {
+			return owner.Kind
+		}
+	}
+	return ""
+}
+
+// Owner returns the controlling owner of a pod as Kind/name, or "-"
+func Owner(pod kubernetes.Pod) string {
+	for _, owner := range pod.Metadata.OwnerReferences {
+		if owner.Controller {
+			return owner.Kind + "/" + owner.Name
+		}
+	}
+	return "-"
+}
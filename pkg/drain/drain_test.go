@@ -0,0 +1,99 @@
+package drain
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+const nodePods = `[
+	{"metadata": {"name": "web-1", "namespace": "shop", "labels": {"app": "web"},
+		"ownerReferences": [{"kind": "ReplicaSet", "name": "web-abc", "controller": true}]}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "cache-0", "namespace": "shop",
+		"ownerReferences": [{"kind": "StatefulSet", "name": "cache", "controller": true}]},
+	 "spec": {"volumes": [{"name": "scratch", "emptyDir": {}}]}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "fluentd-x", "namespace": "logging",
+		"ownerReferences": [{"kind": "DaemonSet", "name": "fluentd", "controller": true}]}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "kube-proxy-node-1", "namespace": "kube-system",
+		"annotations": {"kubernetes.io/config.mirror": "abc"}}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "debug", "namespace": "shop"}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "job-1", "namespace": "batch",
+		"ownerReferences": [{"kind": "Job", "name": "job", "controller": true}]}, "status": {"phase": "Succeeded"}}
+]`
+
+func TestNewPlan(t *testing.T) {
+	var pods []kubernetes.Pod
+	if err := json.Unmarshal([]byte(nodePods), &pods); err != nil {
+		t.Fatalf("Failed to decode pods: %v", err)
+	}
+	var pdbs []kubernetes.PodDisruptionBudget
+	data := `[
+		{"metadata": {"name": "web", "namespace": "shop"}, "spec": {"selector": {"matchLabels": {"app": "web"}}}, "status": {"disruptionsAllowed": 0}},
+		{"metadata": {"name": "other", "namespace": "shop"}, "spec": {"selector": {"matchLabels": {"app": "other"}}}, "status": {"disruptionsAllowed": 0}},
+		{"metadata": {"name": "web-ok", "namespace": "shop"}, "spec": {"selector": {"matchLabels": {"app": "web"}}}, "status": {"disruptionsAllowed": 1}}
+	]`
+	if err := json.Unmarshal([]byte(data), &pdbs); err != nil {
+		t.Fatalf("Failed to decode pdbs: %v", err)
+	}
+
+	plan := NewPlan(pods, pdbs, Options{})
+
+	if len(plan.Evict) != 1 || plan.Evict[0].Metadata.Name != "web-1" {
+		t.Errorf("Expected only web-1 to be evicted, got %+v", plan.Evict)
+	}
+	if len(plan.Ignored) != 3 {
+		t.Errorf("Expected 3 ignored pods (daemonset, static, finished), got %+v", plan.Ignored)
+	}
+	if len(plan.Refused) != 2 {
+		t.Fatalf("Expected 2 refused pods, got %+v", plan.Refused)
+	}
+	if !strings.Contains(plan.Refused[0].Reason, "emptyDir") || !strings.Contains(plan.Refused[1].Reason, "--force") {
+		t.Errorf("Unexpected refuse reasons: %+v", plan.Refused)
+	}
+	if len(plan.Blockers) != 1 || plan.Blockers[0].PDB.Metadata.Name != "web" || plan.Blockers[0].Pods[0] != "shop/web-1" {
+		t.Errorf("Expected PDB web to block shop/web-1, got %+v", plan.Blockers)
+	}
+
+	plan = NewPlan(pods, nil, Options{Force: true, DeleteEmptyDirData: true})
+	if len(plan.Evict) != 3 || len(plan.Refused) != 0 {
+		t.Errorf("Expected 3 pods to evict with --force --delete-emptydir-data, got %d (refused %d)", len(plan.Evict), len(plan.Refused))
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := StatePath(t.TempDir(), "prod", "node-1")
+	if filepath.Base(path) != "prod_node-1.yaml" {
+		t.Errorf("Unexpected state path %s", path)
+	}
+
+	state, err := LoadState(path)
+	if err != nil || state != nil {
+		t.Fatalf("Expected no state for a missing file, got %+v, %v", state, err)
+	}
+
+	state = &State{Node: "node-1", StartedAt: time.Now().UTC().Truncate(time.Second), Pending: []string{"shop/a", "shop/b"}}
+	state.MarkEvicted("shop/a")
+	state.MarkEvicted("shop/a")
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(loaded.Evicted) != 1 || len(loaded.Pending) != 1 || loaded.Pending[0] != "shop/b" {
+		t.Errorf("Unexpected state after reload: %+v", loaded)
+	}
+
+	if err := RemoveState(path); err != nil {
+		t.Fatalf("RemoveState failed: %v", err)
+	}
+	if err := RemoveState(path); err != nil {
+		t.Errorf("Removing a missing state should not fail: %v", err)
+	}
+}
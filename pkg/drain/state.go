@@ -0,0 +1,94 @@
+package drain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stateSubdir is the directory under ~/.kcsi holding drain state files
+const stateSubdir = "drains"
+
+// State records the progress of a drain so an interrupted drain can resume
+type State struct {
+	Node      string    `yaml:"node"`
+	Context   string    `yaml:"context,omitempty"`
+	StartedAt time.Time `yaml:"started_at"`
+	UpdatedAt time.Time `yaml:"updated_at"`
+	Force     bool      `yaml:"force,omitempty"`
+	// DeleteEmptyDirData is the --delete-emptydir-data option of the drain
+	DeleteEmptyDirData bool `yaml:"delete_emptydir_data,omitempty"`
+	// Evicted are the pods (namespace/name) whose eviction was accepted
+	Evicted []string `yaml:"evicted,omitempty"`
+	// Pending are the pods still to evict
+	Pending []string `yaml:"pending,omitempty"`
+}
+
+// StatePath returns the state file of a node drain under the kcsi directory.
+// Drains are tracked per context since node names repeat across clusters.
+func StatePath(kcsiDir, contextName, node string) string {
+	if contextName == "" {
+		contextName = "default"
+	}
+	return filepath.Join(kcsiDir, stateSubdir, contextName+"_"+node+".yaml")
+}
+
+// LoadState reads a drain state file; it returns nil when there is none
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read drain state: %w", err)
+	}
+
+	var state State
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse drain state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state file, creating its directory if needed
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create drain state directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal drain state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write drain state: %w", err)
+	}
+	return nil
+}
+
+// MarkEvicted moves a pod from the pending to the evicted list
+func (s *State) MarkEvicted(key string) {
+	for i, pending := range s.Pending {
+		if pending == key {
+			s.Pending = append(s.Pending[:i], s.Pending[i+1:]...)
+			break
+		}
+	}
+	for _, evicted := range s.Evicted {
+		if evicted == key {
+			return
+		}
+	}
+	s.Evicted = append(s.Evicted, key)
+}
+
+// RemoveState deletes a state file once the drain completed
+func RemoveState(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove drain state: %w", err)
+	}
+	return nil
+}
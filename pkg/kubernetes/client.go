@@ -64,6 +64,15 @@ func ExecuteKubectlInteractive(args ...string) error {
 	return nil
 }
 
+// KubectlCommand returns a kubectl command bound to ctx and configured for the
+// active kcsi context. The caller wires its input and output and runs it,
+// which allows streaming output as it is produced.
+func KubectlCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	setKubeconfigEnv(cmd)
+	return cmd
+}
+
 // InjectDefaultNamespace injects the default namespace from kcsi context if namespace is empty
 // Returns the namespace to use (either the provided one or the default from context)
 func InjectDefaultNamespace(namespace string) string {
@@ -82,6 +91,16 @@ func InjectDefaultNamespace(namespace string) string {
 	return ""
 }
 
+// ResolveNamespace returns the namespace to work in: the provided one, the
+// default of the kcsi context, or else the namespace of the kubectl context
+func ResolveNamespace(namespace string) string {
+	if namespace = InjectDefaultNamespace(namespace); namespace != "" {
+		return namespace
+	}
+	namespace, _ = GetCurrentNamespace()
+	return namespace
+}
+
 // BuildNamespaceArgs builds kubectl args with namespace injection
 // If namespace is empty, tries to use default namespace from kcsi context
 // Returns args with -n flag added if namespace is determined
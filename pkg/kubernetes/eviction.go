@@ -0,0 +1,67 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEvictionBlocked is returned when a PodDisruptionBudget does not allow
+// the eviction yet; the caller may retry later
+var ErrEvictionBlocked = errors.New("eviction blocked by a PodDisruptionBudget")
+
+// EvictPod requests the eviction of a pod through the eviction API, which
+// honours PodDisruptionBudgets. A pod that no longer exists counts as evicted.
+func EvictPod(ctx context.Context, namespace, name string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "policy/v1",
+		"kind":       "Eviction",
+		"metadata":   map[string]string{"name": name, "namespace": namespace},
+	})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/eviction", namespace, name)
+	cmd := KubectlCommand(ctx, "create", "--raw", path, "-f", "-")
+	cmd.Stdin = bytes.NewReader(body)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		switch statusReason(message) {
+		case "NotFound":
+			// NotFound is also returned when the eviction API itself is
+			// missing, so only a pod that is really gone counts as evicted
+			pod, lookupErr := GetObject("pod", name, namespace)
+			if lookupErr == nil && pod == nil {
+				return nil
+			}
+		case "TooManyRequests":
+			// The API answers 429 while a PodDisruptionBudget forbids the eviction
+			return ErrEvictionBlocked
+		}
+		return fmt.Errorf("kubectl error: %v - %s", err, message)
+	}
+	return nil
+}
+
+// statusReason returns the reason of the API Status kubectl prints for a
+// failed request ("Error from server (NotFound): ..." gives "NotFound"), or
+// an empty string for other errors
+func statusReason(message string) string {
+	const prefix = "Error from server ("
+	i := strings.Index(message, prefix)
+	if i < 0 {
+		return ""
+	}
+	reason, _, found := strings.Cut(message[i+len(prefix):], ")")
+	if !found {
+		return ""
+	}
+	return reason
+}
@@ -0,0 +1,21 @@
+package kubernetes
+
+import "testing"
+
+func TestStatusReason(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{`Error from server (NotFound): pods "web-1" not found`, "NotFound"},
+		{"Error from server (NotFound): the server could not find the requested resource", "NotFound"},
+		{"Error from server (TooManyRequests): Cannot evict pod as it would violate the pod's disruption budget.", "TooManyRequests"},
+		{"error: unable to connect to the server", ""},
+		{"Error from server (Forbidden", ""},
+	}
+	for _, tt := range tests {
+		if got := statusReason(tt.message); got != tt.expected {
+			t.Errorf("statusReason(%q) = %q, expected %q", tt.message, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// metricsAPIPath is the base path of the metrics-server API
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// ListPodMetrics returns the current usage of the pods in a namespace from
+// the metrics API. An empty namespace lists pods across all namespaces.
+func ListPodMetrics(namespace string) ([]PodMetrics, error) {
+	path := metricsAPIPath + "/pods"
+	if namespace != "" {
+		path = fmt.Sprintf("%s/namespaces/%s/pods", metricsAPIPath, namespace)
+	}
+
+	output, err := ExecuteKubectl("get", "--raw", path)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "the server could not find the requested resource") {
+			return nil, fmt.Errorf("metrics API not available, is metrics-server installed? (%v)", err)
+		}
+		return nil, err
+	}
+
+	var list struct {
+		Items []PodMetrics `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics API output: %v", err)
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,262 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetJSON runs a kubectl command with JSON output and decodes the result into v
+func GetJSON(v interface{}, args ...string) error {
+	args = append(args, "-o", "json")
+
+	output, err := ExecuteKubectl(args...)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(output), v); err != nil {
+		return fmt.Errorf("failed to parse kubectl JSON output: %v", err)
+	}
+
+	return nil
+}
+
+// GetObject returns a single resource as a generic object.
+// It returns nil without an error if the resource does not exist.
+func GetObject(resource, name, namespace string) (map[string]interface{}, error) {
+	args := []string{"get", resource, name, "--ignore-not-found"}
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	args = append(args, "-o", "json")
+
+	output, err := ExecuteKubectl(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(output) == "" {
+		return nil, nil
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl JSON output: %v", err)
+	}
+
+	return obj, nil
+}
+
+// namespaceScope returns the kubectl args that scope a query to a namespace,
+// or to all namespaces when namespace is empty
+func namespaceScope(namespace string) []string {
+	if namespace == "" {
+		return []string{flagAllNamespaces}
+	}
+	return []string{"-n", namespace}
+}
+
+// ListPods returns the pods matching a label selector (empty for all pods).
+// An empty namespace lists pods across all namespaces.
+func ListPods(namespace, selector string) ([]Pod, error) {
+	args := append([]string{"get", "pods"}, namespaceScope(namespace)...)
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+
+	var list struct {
+		Items []Pod `json:"items"`
+	}
+	if err := GetJSON(&list, args...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListPodsOnNode returns the pods scheduled on a node across all namespaces
+func ListPodsOnNode(node string) ([]Pod, error) {
+	var list struct {
+		Items []Pod `json:"items"`
+	}
+	if err := GetJSON(&list, "get", "pods", flagAllNamespaces, "--field-selector", "spec.nodeName="+node); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetPod returns a single pod
+func GetPod(namespace, podName string) (*Pod, error) {
+	if podName == "" {
+		return nil, fmt.Errorf("pod name is required")
+	}
+
+	args := []string{"get", "pod", podName}
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+
+	var pod Pod
+	if err := GetJSON(&pod, args...); err != nil {
+		return nil, err
+	}
+	return &pod, nil
+}
+
+// GetService returns a single service
+func GetService(name, namespace string) (*Service, error) {
+	var service Service
+	if err := GetJSON(&service, "get", "service", name, "-n", namespace); err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// GetWorkload returns a deployment, statefulset, daemonset or replicaset
+func GetWorkload(resourceType, name, namespace string) (*Workload, error) {
+	var workload Workload
+	if err := GetJSON(&workload, "get", resourceType, name, "-n", namespace); err != nil {
+		return nil, err
+	}
+	return &workload, nil
+}
+
+// ListWorkloads returns the deployments, statefulsets, daemonsets or replicasets
+// of a namespace. An empty namespace lists them across all namespaces.
+func ListWorkloads(resourceType, namespace string) ([]Workload, error) {
+	var list struct {
+		Items []Workload `json:"items"`
+	}
+	args := append([]string{"get", resourceType}, namespaceScope(namespace)...)
+	if err := GetJSON(&list, args...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListReplicaSets returns the replicasets matching a label selector
+func ListReplicaSets(namespace, selector string) ([]Workload, error) {
+	args := []string{"get", "replicasets", "-n", namespace}
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+
+	var list struct {
+		Items []Workload `json:"items"`
+	}
+	if err := GetJSON(&list, args...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListControllerRevisions returns the controllerrevisions matching a label selector
+func ListControllerRevisions(namespace, selector string) ([]ControllerRevision, error) {
+	args := []string{"get", "controllerrevisions", "-n", namespace}
+	if selector != "" {
+		args = append(args, "-l", selector)
+	}
+
+	var list struct {
+		Items []ControllerRevision `json:"items"`
+	}
+	if err := GetJSON(&list, args...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListHPAs returns the horizontal pod autoscalers in a namespace
+func ListHPAs(namespace string) ([]HorizontalPodAutoscaler, error) {
+	var list struct {
+		Items []HorizontalPodAutoscaler `json:"items"`
+	}
+	if err := GetJSON(&list, "get", "horizontalpodautoscalers", "-n", namespace); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetEvents returns events matching a field selector, oldest first.
+// An empty namespace lists events across all namespaces.
+func GetEvents(namespace, fieldSelector string) ([]Event, error) {
+	args := append([]string{"get", "events"}, namespaceScope(namespace)...)
+	if fieldSelector != "" {
+		args = append(args, "--field-selector", fieldSelector)
+	}
+
+	var list struct {
+		Items []Event `json:"items"`
+	}
+	if err := GetJSON(&list, args...); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[i].Timestamp().Before(list.Items[j].Timestamp())
+	})
+	return list.Items, nil
+}
+
+// GetPodEvents returns the events recorded for a pod, oldest first
+func GetPodEvents(namespace, podName string) ([]Event, error) {
+	return GetEvents(namespace, fmt.Sprintf("involvedObject.kind=Pod,involvedObject.name=%s", podName))
+}
+
+// ListNodes returns all nodes of the cluster
+func ListNodes() ([]Node, error) {
+	var list struct {
+		Items []Node `json:"items"`
+	}
+	if err := GetJSON(&list, "get", "nodes"); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetNode returns a single node
+func GetNode(name string) (*Node, error) {
+	var node Node
+	if err := GetJSON(&node, "get", "node", name); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// ListPVCs returns the persistent volume claims of a namespace.
+// An empty namespace lists them across all namespaces.
+func ListPVCs(namespace string) ([]PersistentVolumeClaim, error) {
+	var list struct {
+		Items []PersistentVolumeClaim `json:"items"`
+	}
+	args := append([]string{"get", "persistentvolumeclaims"}, namespaceScope(namespace)...)
+	if err := GetJSON(&list, args...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListPDBs returns the pod disruption budgets of a namespace.
+// An empty namespace lists them across all namespaces.
+func ListPDBs(namespace string) ([]PodDisruptionBudget, error) {
+	var list struct {
+		Items []PodDisruptionBudget `json:"items"`
+	}
+	args := append([]string{"get", "poddisruptionbudgets"}, namespaceScope(namespace)...)
+	if err := GetJSON(&list, args...); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListNamespaces returns all namespaces of the cluster
+func ListNamespaces() ([]Namespace, error) {
+	var list struct {
+		Items []Namespace `json:"items"`
+	}
+	if err := GetJSON(&list, "get", "namespaces"); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
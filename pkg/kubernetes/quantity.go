@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// quantitySuffixes maps Kubernetes quantity suffixes to their multiplier
+var quantitySuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"Pi", 1 << 50},
+	{"Ei", 1 << 60},
+	{"n", 1e-9},
+	{"u", 1e-6},
+	{"m", 1e-3},
+	{"k", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+	{"P", 1e15},
+	{"E", 1e18},
+}
+
+// ParseQuantity converts a Kubernetes resource quantity to its base unit:
+// cores for CPU ("250m" → 0.25) and bytes for memory ("128Mi" → 134217728)
+func ParseQuantity(quantity string) (float64, error) {
+	quantity = strings.TrimSpace(quantity)
+	if quantity == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+
+	multiplier := 1.0
+	number := quantity
+	for _, s := range quantitySuffixes {
+		if strings.HasSuffix(quantity, s.suffix) {
+			multiplier = s.multiplier
+			number = strings.TrimSuffix(quantity, s.suffix)
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity '%s'", quantity)
+	}
+	return value * multiplier, nil
+}
+
+// FormatCPU renders a number of cores the way kubectl does ("250m", "2")
+func FormatCPU(cores float64) string {
+	millis := int64(cores*1000 + 0.5)
+	if millis%1000 == 0 {
+		return strconv.FormatInt(millis/1000, 10)
+	}
+	return fmt.Sprintf("%dm", millis)
+}
+
+// FormatMemory renders a number of bytes with a binary suffix ("256Mi", "1.5Gi")
+func FormatMemory(bytes float64) string {
+	units := []string{"Ki", "Mi", "Gi", "Ti"}
+	value := bytes
+	unit := ""
+	for _, u := range units {
+		if value < 1024 {
+			break
+		}
+		value /= 1024
+		unit = u
+	}
+	if unit == "" {
+		return strconv.FormatInt(int64(value), 10)
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(value, 'f', 1, 64), ".0") + unit
+}
@@ -0,0 +1,54 @@
+package kubernetes
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		expected float64
+	}{
+		{"250m", 0.25},
+		{"2", 2},
+		{"0.5", 0.5},
+		{"128Mi", 128 * 1024 * 1024},
+		{"1Gi", 1024 * 1024 * 1024},
+		{"1G", 1e9},
+		{"500k", 500000},
+		{"1024", 1024},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseQuantity(tt.quantity)
+		if err != nil {
+			t.Errorf("ParseQuantity(%q) returned error: %v", tt.quantity, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseQuantity(%q) = %v, expected %v", tt.quantity, got, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "abc", "12Xi"} {
+		if _, err := ParseQuantity(invalid); err == nil {
+			t.Errorf("Expected error for quantity %q", invalid)
+		}
+	}
+}
+
+func TestFormatQuantities(t *testing.T) {
+	if got := FormatCPU(0.25); got != "250m" {
+		t.Errorf("Expected 250m, got %s", got)
+	}
+	if got := FormatCPU(2); got != "2" {
+		t.Errorf("Expected 2, got %s", got)
+	}
+	if got := FormatMemory(256 * 1024 * 1024); got != "256Mi" {
+		t.Errorf("Expected 256Mi, got %s", got)
+	}
+	if got := FormatMemory(1.5 * 1024 * 1024 * 1024); got != "1.5Gi" {
+		t.Errorf("Expected 1.5Gi, got %s", got)
+	}
+	if got := FormatMemory(512); got != "512" {
+		t.Errorf("Expected 512, got %s", got)
+	}
+}
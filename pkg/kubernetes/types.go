@@ -0,0 +1,521 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ObjectMeta holds the metadata fields kcsi reads from Kubernetes objects
+type ObjectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	UID               string            `json:"uid"`
+	Labels            map[string]string `json:"labels"`
+	Annotations       map[string]string `json:"annotations"`
+	Generation        int64             `json:"generation"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	DeletionTimestamp *time.Time        `json:"deletionTimestamp"`
+	OwnerReferences   []OwnerReference  `json:"ownerReferences"`
+}
+
+// OwnerReference identifies the controller that owns an object
+type OwnerReference struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller bool   `json:"controller"`
+}
+
+// LabelSelector is a Kubernetes label selector
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions"`
+}
+
+// LabelSelectorRequirement is a single set-based selector requirement
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// String returns the selector in kubectl -l syntax
+func (s LabelSelector) String() string {
+	keys := make([]string, 0, len(s.MatchLabels))
+	for key := range s.MatchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+len(s.MatchExpressions))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, s.MatchLabels[key]))
+	}
+
+	for _, expr := range s.MatchExpressions {
+		switch expr.Operator {
+		case "In":
+			parts = append(parts, fmt.Sprintf("%s in (%s)", expr.Key, strings.Join(expr.Values, ",")))
+		case "NotIn":
+			parts = append(parts, fmt.Sprintf("%s notin (%s)", expr.Key, strings.Join(expr.Values, ",")))
+		case "Exists":
+			parts = append(parts, expr.Key)
+		case "DoesNotExist":
+			parts = append(parts, "!"+expr.Key)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// Matches reports whether a set of labels satisfies the selector.
+// An empty selector matches everything.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for key, value := range s.MatchLabels {
+		if labels[key] != value {
+			return false
+		}
+	}
+
+	for _, expr := range s.MatchExpressions {
+		value, exists := labels[expr.Key]
+		switch expr.Operator {
+		case "In":
+			if !exists || !containsString(expr.Values, value) {
+				return false
+			}
+		case "NotIn":
+			if exists && containsString(expr.Values, value) {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Pod is the subset of a Kubernetes pod used by kcsi
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+	Status   PodStatus  `json:"status"`
+}
+
+// PodSpec describes the containers and scheduling of a pod
+type PodSpec struct {
+	NodeName       string      `json:"nodeName"`
+	RestartPolicy  string      `json:"restartPolicy"`
+	Containers     []Container `json:"containers"`
+	InitContainers []Container `json:"initContainers"`
+	Volumes        []Volume    `json:"volumes"`
+}
+
+// Volume is a pod volume; only the source types kcsi inspects are decoded
+type Volume struct {
+	Name     string    `json:"name"`
+	EmptyDir *struct{} `json:"emptyDir"`
+}
+
+// Container describes a container in a pod spec
+type Container struct {
+	Name           string               `json:"name"`
+	Image          string               `json:"image"`
+	Ports          []ContainerPort      `json:"ports"`
+	Resources      ResourceRequirements `json:"resources"`
+	LivenessProbe  *Probe               `json:"livenessProbe"`
+	ReadinessProbe *Probe               `json:"readinessProbe"`
+	StartupProbe   *Probe               `json:"startupProbe"`
+}
+
+// ContainerPort is a port exposed by a container
+type ContainerPort struct {
+	Name          string `json:"name"`
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+// ResourceRequirements holds container requests and limits as quantity strings
+type ResourceRequirements struct {
+	Requests map[string]string `json:"requests"`
+	Limits   map[string]string `json:"limits"`
+}
+
+// Probe describes a liveness, readiness or startup probe
+type Probe struct {
+	HTTPGet *struct {
+		Path string      `json:"path"`
+		Port interface{} `json:"port"`
+	} `json:"httpGet"`
+	TCPSocket *struct {
+		Port interface{} `json:"port"`
+	} `json:"tcpSocket"`
+	Exec *struct {
+		Command []string `json:"command"`
+	} `json:"exec"`
+	InitialDelaySeconds int `json:"initialDelaySeconds"`
+	TimeoutSeconds      int `json:"timeoutSeconds"`
+	PeriodSeconds       int `json:"periodSeconds"`
+	FailureThreshold    int `json:"failureThreshold"`
+}
+
+// PodStatus is the observed state of a pod
+type PodStatus struct {
+	Phase                 string            `json:"phase"`
+	Reason                string            `json:"reason"`
+	Message               string            `json:"message"`
+	Conditions            []Condition       `json:"conditions"`
+	ContainerStatuses     []ContainerStatus `json:"containerStatuses"`
+	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses"`
+	StartTime             *time.Time        `json:"startTime"`
+}
+
+// Condition is a status condition of a pod, node or workload
+type Condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// ContainerStatus is the observed state of a single container
+type ContainerStatus struct {
+	Name         string         `json:"name"`
+	Image        string         `json:"image"`
+	Ready        bool           `json:"ready"`
+	RestartCount int            `json:"restartCount"`
+	State        ContainerState `json:"state"`
+	LastState    ContainerState `json:"lastState"`
+}
+
+// ContainerState holds exactly one of the waiting, running or terminated states
+type ContainerState struct {
+	Waiting *struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	} `json:"waiting"`
+	Running *struct {
+		StartedAt time.Time `json:"startedAt"`
+	} `json:"running"`
+	Terminated *TerminatedState `json:"terminated"`
+}
+
+// TerminatedState describes why and when a container stopped
+type TerminatedState struct {
+	ExitCode   int       `json:"exitCode"`
+	Reason     string    `json:"reason"`
+	Message    string    `json:"message"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// IsReady reports whether the pod has the Ready condition set to True
+func (p Pod) IsReady() bool {
+	for _, cond := range p.Status.Conditions {
+		if cond.Type == "Ready" {
+			return cond.Status == "True"
+		}
+	}
+	return false
+}
+
+// DefaultContainerAnnotation names the container kubectl exec, logs and cp
+// use when no container is given
+const DefaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// Container returns the named container, or the default container (the
+// annotated one, otherwise the first) when name is empty
+func (p Pod) Container(name string) (Container, bool) {
+	if name == "" {
+		name = p.Metadata.Annotations[DefaultContainerAnnotation]
+		if name == "" && len(p.Spec.Containers) > 0 {
+			return p.Spec.Containers[0], true
+		}
+	}
+	for _, c := range p.Spec.Containers {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Container{}, false
+}
+
+// RestartCount returns the total number of container restarts in the pod
+func (p Pod) RestartCount() int {
+	total := 0
+	for _, cs := range p.Status.ContainerStatuses {
+		total += cs.RestartCount
+	}
+	return total
+}
+
+// PodTemplateSpec is the pod template of a workload
+type PodTemplateSpec struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+}
+
+// Workload is the subset of deployments, statefulsets, daemonsets and
+// replicasets used by kcsi
+type Workload struct {
+	Kind     string         `json:"kind"`
+	Metadata ObjectMeta     `json:"metadata"`
+	Spec     WorkloadSpec   `json:"spec"`
+	Status   WorkloadStatus `json:"status"`
+}
+
+// WorkloadSpec is the desired state of a workload
+type WorkloadSpec struct {
+	Replicas *int            `json:"replicas"`
+	Paused   bool            `json:"paused"`
+	Selector LabelSelector   `json:"selector"`
+	Template PodTemplateSpec `json:"template"`
+}
+
+// WorkloadStatus merges the status fields of the workload kinds.
+// Deployments, statefulsets and replicasets report replicas, daemonsets
+// report scheduled numbers.
+type WorkloadStatus struct {
+	ObservedGeneration     int64       `json:"observedGeneration"`
+	Replicas               int         `json:"replicas"`
+	UpdatedReplicas        int         `json:"updatedReplicas"`
+	ReadyReplicas          int         `json:"readyReplicas"`
+	AvailableReplicas      int         `json:"availableReplicas"`
+	CurrentRevision        string      `json:"currentRevision"`
+	UpdateRevision         string      `json:"updateRevision"`
+	DesiredNumberScheduled int         `json:"desiredNumberScheduled"`
+	UpdatedNumberScheduled int         `json:"updatedNumberScheduled"`
+	NumberReady            int         `json:"numberReady"`
+	NumberAvailable        int         `json:"numberAvailable"`
+	Conditions             []Condition `json:"conditions"`
+}
+
+// DesiredReplicas returns spec.replicas, which defaults to 1 when unset
+func (w Workload) DesiredReplicas() int {
+	if w.Spec.Replicas == nil {
+		return 1
+	}
+	return *w.Spec.Replicas
+}
+
+// ControllerRevision is a pod template snapshot kept for a revision of a
+// statefulset or daemonset
+type ControllerRevision struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Revision int64      `json:"revision"`
+	Data     struct {
+		Spec struct {
+			Template json.RawMessage `json:"template"`
+		} `json:"spec"`
+	} `json:"data"`
+}
+
+// HorizontalPodAutoscaler is the subset of an HPA used by kcsi
+type HorizontalPodAutoscaler struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     struct {
+		ScaleTargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"scaleTargetRef"`
+		MinReplicas *int `json:"minReplicas"`
+		MaxReplicas int  `json:"maxReplicas"`
+	} `json:"spec"`
+}
+
+// Event is a Kubernetes event
+type Event struct {
+	Metadata       ObjectMeta `json:"metadata"`
+	InvolvedObject struct {
+		Kind      string `json:"kind"`
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		FieldPath string `json:"fieldPath"`
+	} `json:"involvedObject"`
+	Type           string    `json:"type"`
+	Reason         string    `json:"reason"`
+	Message        string    `json:"message"`
+	Count          int       `json:"count"`
+	FirstTimestamp time.Time `json:"firstTimestamp"`
+	LastTimestamp  time.Time `json:"lastTimestamp"`
+	EventTime      time.Time `json:"eventTime"`
+}
+
+// Timestamp returns the most recent time the event was observed
+func (e Event) Timestamp() time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime
+	}
+	return e.Metadata.CreationTimestamp
+}
+
+// Node is the subset of a Kubernetes node used by kcsi
+type Node struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Unschedulable bool    `json:"unschedulable"`
+		Taints        []Taint `json:"taints"`
+	} `json:"spec"`
+	Status struct {
+		Conditions  []Condition       `json:"conditions"`
+		Capacity    map[string]string `json:"capacity"`
+		Allocatable map[string]string `json:"allocatable"`
+	} `json:"status"`
+}
+
+// Taint is a node taint
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Effect string `json:"effect"`
+}
+
+// String returns the taint in kubectl syntax (key=value:Effect)
+func (t Taint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + t.Effect
+	}
+	return t.Key + "=" + t.Value + ":" + t.Effect
+}
+
+// IsReady reports whether the node has the Ready condition set to True
+func (n Node) IsReady() bool {
+	for _, cond := range n.Status.Conditions {
+		if cond.Type == "Ready" {
+			return cond.Status == "True"
+		}
+	}
+	return false
+}
+
+// NodePressureConditions are node conditions that signal a problem when True
+var NodePressureConditions = []string{"MemoryPressure", "DiskPressure", "PIDPressure", "NetworkUnavailable"}
+
+// PressureConditions returns the pressure conditions currently True on the node
+func (n Node) PressureConditions() []Condition {
+	var active []Condition
+	for _, cond := range n.Status.Conditions {
+		if cond.Status != "True" {
+			continue
+		}
+		for _, pressure := range NodePressureConditions {
+			if cond.Type == pressure {
+				active = append(active, cond)
+			}
+		}
+	}
+	return active
+}
+
+// PersistentVolumeClaim is the subset of a PVC used by kcsi
+type PersistentVolumeClaim struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     struct {
+		StorageClassName *string `json:"storageClassName"`
+		VolumeName       string  `json:"volumeName"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+// PodDisruptionBudget is the subset of a PDB used by kcsi
+type PodDisruptionBudget struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Selector       LabelSelector `json:"selector"`
+		MinAvailable   interface{}   `json:"minAvailable"`
+		MaxUnavailable interface{}   `json:"maxUnavailable"`
+	} `json:"spec"`
+	Status struct {
+		DisruptionsAllowed int `json:"disruptionsAllowed"`
+		CurrentHealthy     int `json:"currentHealthy"`
+		DesiredHealthy     int `json:"desiredHealthy"`
+		ExpectedPods       int `json:"expectedPods"`
+	} `json:"status"`
+}
+
+// Namespace is the subset of a Kubernetes namespace used by kcsi
+type Namespace struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Status   struct {
+		Phase      string      `json:"phase"`
+		Conditions []Condition `json:"conditions"`
+	} `json:"status"`
+}
+
+// PodMetrics is the current resource usage of a pod from the metrics API
+type PodMetrics struct {
+	Metadata   ObjectMeta `json:"metadata"`
+	Timestamp  time.Time  `json:"timestamp"`
+	Containers []struct {
+		Name  string            `json:"name"`
+		Usage map[string]string `json:"usage"`
+	} `json:"containers"`
+}
+
+// IntOrString is a field holding either a number or a name, such as a
+// service target port
+type IntOrString struct {
+	IntVal   int
+	StrVal   string
+	IsString bool
+}
+
+// UnmarshalJSON decodes a JSON number or string
+func (v *IntOrString) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		v.IsString = true
+		return json.Unmarshal(data, &v.StrVal)
+	}
+	v.IsString = false
+	return json.Unmarshal(data, &v.IntVal)
+}
+
+// String returns the name or the number
+func (v IntOrString) String() string {
+	if v.IsString {
+		return v.StrVal
+	}
+	return fmt.Sprintf("%d", v.IntVal)
+}
+
+// Service is the subset of a service used by kcsi
+type Service struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Type     string            `json:"type"`
+		Selector map[string]string `json:"selector"`
+		Ports    []ServicePort     `json:"ports"`
+	} `json:"spec"`
+}
+
+// ServicePort is a port exposed by a service
+type ServicePort struct {
+	Name       string       `json:"name"`
+	Protocol   string       `json:"protocol"`
+	Port       int          `json:"port"`
+	TargetPort *IntOrString `json:"targetPort"`
+}
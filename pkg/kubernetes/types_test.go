@@ -0,0 +1,154 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLabelSelectorString(t *testing.T) {
+	selector := LabelSelector{
+		MatchLabels: map[string]string{"tier": "web", "app": "shop"},
+		MatchExpressions: []LabelSelectorRequirement{
+			{Key: "env", Operator: "In", Values: []string{"prod", "staging"}},
+			{Key: "canary", Operator: "DoesNotExist"},
+		},
+	}
+
+	expected := "app=shop,tier=web,env in (prod,staging),!canary"
+	if got := selector.String(); got != expected {
+		t.Errorf("Expected selector %q, got %q", expected, got)
+	}
+}
+
+func TestPodDecodeAndHelpers(t *testing.T) {
+	data := `{
+		"metadata": {"name": "web-1", "namespace": "shop", "deletionTimestamp": null},
+		"status": {
+			"phase": "Running",
+			"conditions": [{"type": "Ready", "status": "True"}],
+			"containerStatuses": [
+				{"name": "app", "restartCount": 2, "state": {"running": {"startedAt": "2026-01-01T10:00:00Z"}}},
+				{"name": "proxy", "restartCount": 1, "lastState": {"terminated": {"exitCode": 137, "reason": "OOMKilled"}}}
+			]
+		}
+	}`
+
+	var pod Pod
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod: %v", err)
+	}
+
+	if !pod.IsReady() {
+		t.Error("Expected pod to be ready")
+	}
+	if pod.RestartCount() != 3 {
+		t.Errorf("Expected 3 restarts, got %d", pod.RestartCount())
+	}
+	if pod.Status.ContainerStatuses[1].LastState.Terminated.Reason != "OOMKilled" {
+		t.Error("Expected last termination reason to be decoded")
+	}
+}
+
+func TestWorkloadDesiredReplicas(t *testing.T) {
+	var workload Workload
+	if workload.DesiredReplicas() != 1 {
+		t.Errorf("Expected default of 1 replica, got %d", workload.DesiredReplicas())
+	}
+
+	replicas := 0
+	workload.Spec.Replicas = &replicas
+	if workload.DesiredReplicas() != 0 {
+		t.Errorf("Expected 0 replicas, got %d", workload.DesiredReplicas())
+	}
+}
+
+func TestEventTimestamp(t *testing.T) {
+	created := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	last := created.Add(time.Minute)
+
+	event := Event{}
+	event.Metadata.CreationTimestamp = created
+	if !event.Timestamp().Equal(created) {
+		t.Errorf("Expected creation timestamp fallback, got %v", event.Timestamp())
+	}
+
+	event.LastTimestamp = last
+	if !event.Timestamp().Equal(last) {
+		t.Errorf("Expected last timestamp, got %v", event.Timestamp())
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	selector := LabelSelector{
+		MatchLabels: map[string]string{"app": "web"},
+		MatchExpressions: []LabelSelectorRequirement{
+			{Key: "env", Operator: "In", Values: []string{"prod", "staging"}},
+			{Key: "canary", Operator: "DoesNotExist"},
+		},
+	}
+
+	tests := []struct {
+		labels   map[string]string
+		expected bool
+	}{
+		{map[string]string{"app": "web", "env": "prod"}, true},
+		{map[string]string{"app": "web", "env": "dev"}, false},
+		{map[string]string{"app": "web", "env": "prod", "canary": "true"}, false},
+		{map[string]string{"app": "api", "env": "prod"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := selector.Matches(tt.labels); got != tt.expected {
+			t.Errorf("Matches(%v) = %v, expected %v", tt.labels, got, tt.expected)
+		}
+	}
+
+	if !(LabelSelector{}).Matches(map[string]string{"any": "thing"}) {
+		t.Error("Expected empty selector to match everything")
+	}
+}
+
+func TestServiceTargetPortDecode(t *testing.T) {
+	data := `{"spec": {"ports": [
+		{"name": "http", "port": 80, "targetPort": "web"},
+		{"name": "metrics", "port": 9090, "targetPort": 9091},
+		{"port": 5432}
+	]}}`
+
+	var svc Service
+	if err := json.Unmarshal([]byte(data), &svc); err != nil {
+		t.Fatalf("Failed to decode service: %v", err)
+	}
+
+	ports := svc.Spec.Ports
+	if !ports[0].TargetPort.IsString || ports[0].TargetPort.String() != "web" {
+		t.Errorf("Expected named target port web, got %+v", ports[0].TargetPort)
+	}
+	if ports[1].TargetPort.IsString || ports[1].TargetPort.IntVal != 9091 {
+		t.Errorf("Expected numeric target port 9091, got %+v", ports[1].TargetPort)
+	}
+	if ports[2].TargetPort != nil {
+		t.Errorf("Expected no target port, got %+v", ports[2].TargetPort)
+	}
+}
+
+func TestPodContainer(t *testing.T) {
+	pod := Pod{Spec: PodSpec{Containers: []Container{{Name: "app", Image: "shop/web:1"}, {Name: "proxy", Image: "envoy:1"}}}}
+
+	if c, ok := pod.Container(""); !ok || c.Name != "app" {
+		t.Errorf("Expected the first container by default, got %+v", c)
+	}
+	if c, ok := pod.Container("proxy"); !ok || c.Image != "envoy:1" {
+		t.Errorf("Expected the proxy container, got %+v", c)
+	}
+	if _, ok := pod.Container("missing"); ok {
+		t.Error("Expected no container named missing")
+	}
+
+	pod.Metadata.Annotations = map[string]string{DefaultContainerAnnotation: "proxy"}
+	if c, _ := pod.Container(""); c.Name != "proxy" {
+		t.Errorf("Expected the annotated default container, got %+v", c)
+	}
+}
@@ -0,0 +1,297 @@
+package logfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldAliases maps well-known field names to the keys commonly used by logging libraries
+var fieldAliases = map[string][]string{
+	"ts":    {"ts", "time", "timestamp", "@timestamp", "t"},
+	"level": {"level", "lvl", "severity", "log.level", "levelname"},
+	"msg":   {"msg", "message", "@message"},
+}
+
+// levelRanks orders log levels by severity
+var levelRanks = map[string]int{
+	"trace":    10,
+	"debug":    20,
+	"info":     30,
+	"warn":     40,
+	"warning":  40,
+	"error":    50,
+	"err":      50,
+	"fatal":    60,
+	"critical": 60,
+	"panic":    60,
+}
+
+// condition is a --where filter on a JSON field
+type condition struct {
+	Field  string
+	Value  string
+	Negate bool
+}
+
+// Pipeline parses, filters and formats log lines.
+// JSON lines can be filtered by level and field values and reduced to selected
+// fields. Non-JSON lines pass through unchanged, only --grep applies to them.
+type Pipeline struct {
+	fields     []string
+	minLevel   int
+	exactLevel bool
+	conditions []condition
+	grep       *regexp.Regexp
+
+	// Timestamped is set when lines are prefixed with the RFC3339 timestamp
+	// kubectl adds with --timestamps. The timestamp is split off before the
+	// line is processed and only printed again if ShowTimestamps is set.
+	Timestamped    bool
+	ShowTimestamps bool
+
+	// Until drops timestamped lines logged after this time
+	Until time.Time
+
+	// Highlight decorates grep matches, e.g. with terminal colors
+	Highlight func(string) string
+}
+
+// NewPipeline builds a pipeline from the logs command options.
+// level is a level name, optionally suffixed with + to include more severe
+// levels (e.g. warn+). where entries have the form key=value or key!=value.
+func NewPipeline(fields []string, level string, where []string, grep string) (*Pipeline, error) {
+	p := &Pipeline{fields: fields}
+
+	if level != "" {
+		name := strings.ToLower(strings.TrimSuffix(level, "+"))
+		rank, ok := levelRanks[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown log level '%s' (use trace, debug, info, warn, error or fatal)", name)
+		}
+		p.minLevel = rank
+		p.exactLevel = !strings.HasSuffix(level, "+")
+	}
+
+	for _, expr := range where {
+		cond, err := parseCondition(expr)
+		if err != nil {
+			return nil, err
+		}
+		p.conditions = append(p.conditions, cond)
+	}
+
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep pattern: %v", err)
+		}
+		p.grep = re
+	}
+
+	return p, nil
+}
+
+func parseCondition(expr string) (condition, error) {
+	if key, value, found := strings.Cut(expr, "!="); found && key != "" {
+		return condition{Field: key, Value: value, Negate: true}, nil
+	}
+	if key, value, found := strings.Cut(expr, "="); found && key != "" {
+		return condition{Field: key, Value: value}, nil
+	}
+	return condition{}, fmt.Errorf("invalid --where expression '%s', expected key=value or key!=value", expr)
+}
+
+// Active reports whether the pipeline changes or filters any line
+func (p *Pipeline) Active() bool {
+	return len(p.fields) > 0 || p.minLevel > 0 || len(p.conditions) > 0 || p.grep != nil || p.Timestamped
+}
+
+// Process returns the line to print and whether it passed the filters
+func (p *Pipeline) Process(line string) (string, bool) {
+	if !p.Timestamped {
+		return p.process(line)
+	}
+
+	ts, rest, ok := SplitTimestamp(line)
+	if !ok {
+		return p.process(line)
+	}
+	if !p.Until.IsZero() && ts.After(p.Until) {
+		return "", false
+	}
+
+	out, shown := p.process(rest)
+	if shown && p.ShowTimestamps {
+		out = line[:len(line)-len(rest)] + out
+	}
+	return out, shown
+}
+
+// SplitTimestamp splits the leading RFC3339 timestamp off a log line
+func SplitTimestamp(line string) (time.Time, string, bool) {
+	prefix, rest, found := strings.Cut(line, " ")
+	if !found {
+		prefix, rest = line, ""
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return ts, rest, true
+}
+
+func (p *Pipeline) process(line string) (string, bool) {
+	if p.grep != nil && !p.grep.MatchString(line) {
+		return "", false
+	}
+
+	entry := parseJSON(line)
+	if entry == nil {
+		return p.highlight(line), true
+	}
+
+	if !p.matchLevel(entry) || !p.matchConditions(entry) {
+		return "", false
+	}
+
+	if len(p.fields) == 0 {
+		return p.highlight(line), true
+	}
+	return p.highlight(p.format(entry)), true
+}
+
+func parseJSON(line string) map[string]interface{} {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &entry); err != nil {
+		return nil
+	}
+	return entry
+}
+
+func (p *Pipeline) matchLevel(entry map[string]interface{}) bool {
+	if p.minLevel == 0 {
+		return true
+	}
+
+	value, ok := lookup(entry, "level")
+	if !ok {
+		return false
+	}
+
+	rank := levelRank(value)
+	if p.exactLevel {
+		return rank == p.minLevel
+	}
+	return rank >= p.minLevel
+}
+
+// levelRank converts a level name or a numeric level (as used by pino and
+// bunyan) to a rank
+func levelRank(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v) / 10 * 10
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n / 10 * 10
+		}
+		return levelRanks[strings.ToLower(v)]
+	}
+	return 0
+}
+
+func (p *Pipeline) matchConditions(entry map[string]interface{}) bool {
+	for _, cond := range p.conditions {
+		value, ok := lookup(entry, cond.Field)
+		equal := ok && formatValue(value) == cond.Value
+		if equal == cond.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// format renders the selected fields separated by spaces
+func (p *Pipeline) format(entry map[string]interface{}) string {
+	parts := make([]string, 0, len(p.fields))
+	for _, field := range p.fields {
+		value, ok := lookup(entry, field)
+		if !ok {
+			parts = append(parts, "-")
+			continue
+		}
+		text := formatValue(value)
+		if field == "level" {
+			text = strings.ToUpper(text)
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *Pipeline) highlight(line string) string {
+	if p.grep == nil || p.Highlight == nil {
+		return line
+	}
+	return p.grep.ReplaceAllStringFunc(line, p.Highlight)
+}
+
+// lookup finds a field by alias, literal key or dotted path into nested objects
+func lookup(entry map[string]interface{}, field string) (interface{}, bool) {
+	keys := fieldAliases[field]
+	if keys == nil {
+		keys = []string{field}
+	}
+
+	for _, key := range keys {
+		if value, ok := entry[key]; ok {
+			return value, true
+		}
+		if value, ok := lookupPath(entry, key); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func lookupPath(entry map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = entry
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "null"
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,156 @@
+package logfilter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNonJSONPassesThrough(t *testing.T) {
+	p, err := NewPipeline([]string{"msg"}, "error+", []string{"app=web"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	line := "plain text line {not json"
+	out, ok := p.Process(line)
+	if !ok || out != line {
+		t.Errorf("Expected non-JSON line to pass through unchanged, got %q (shown: %v)", out, ok)
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	tests := []struct {
+		level    string
+		line     string
+		expected bool
+	}{
+		{"warn+", `{"level":"info","msg":"a"}`, false},
+		{"warn+", `{"level":"warning","msg":"a"}`, true},
+		{"warn+", `{"severity":"ERROR","msg":"a"}`, true},
+		{"warn+", `{"level":50,"msg":"pino error"}`, true},
+		{"warn", `{"level":"error","msg":"a"}`, false},
+		{"warn+", `{"msg":"no level"}`, false},
+	}
+
+	for _, tt := range tests {
+		p, err := NewPipeline(nil, tt.level, nil, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := p.Process(tt.line); ok != tt.expected {
+			t.Errorf("level %s, line %s: expected shown=%v, got %v", tt.level, tt.line, tt.expected, ok)
+		}
+	}
+}
+
+func TestUnknownLevel(t *testing.T) {
+	if _, err := NewPipeline(nil, "loud+", nil, ""); err == nil {
+		t.Error("Expected error for unknown level, got nil")
+	}
+}
+
+func TestWhereConditions(t *testing.T) {
+	p, err := NewPipeline(nil, "", []string{"http.status=500", "route!=/health"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{`{"http":{"status":500},"route":"/api"}`, true},
+		{`{"http":{"status":200},"route":"/api"}`, false},
+		{`{"http":{"status":500},"route":"/health"}`, false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := p.Process(tt.line); ok != tt.expected {
+			t.Errorf("line %s: expected shown=%v, got %v", tt.line, tt.expected, ok)
+		}
+	}
+}
+
+func TestInvalidWhere(t *testing.T) {
+	if _, err := NewPipeline(nil, "", []string{"novalue"}, ""); err == nil {
+		t.Error("Expected error for invalid --where expression, got nil")
+	}
+}
+
+func TestFieldsFormatting(t *testing.T) {
+	p, err := NewPipeline([]string{"ts", "level", "msg", "user"}, "", nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, ok := p.Process(`{"time":"10:00:00","level":"warn","message":"slow query"}`)
+	if !ok {
+		t.Fatal("Expected line to be shown")
+	}
+	if out != "10:00:00 WARN slow query -" {
+		t.Errorf("Unexpected formatting: %q", out)
+	}
+}
+
+func TestGrepHighlight(t *testing.T) {
+	p, err := NewPipeline(nil, "", nil, "time(out)?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Highlight = func(s string) string { return "<" + s + ">" }
+
+	if _, ok := p.Process("all good"); ok {
+		t.Error("Expected non-matching line to be filtered")
+	}
+
+	out, ok := p.Process("request timeout after 5s")
+	if !ok || out != "request <timeout> after 5s" {
+		t.Errorf("Unexpected highlight result: %q (shown: %v)", out, ok)
+	}
+}
+
+func TestActive(t *testing.T) {
+	p, _ := NewPipeline(nil, "", nil, "")
+	if p.Active() {
+		t.Error("Expected empty pipeline to be inactive")
+	}
+	p, _ = NewPipeline([]string{"msg"}, "", nil, "")
+	if !p.Active() {
+		t.Error("Expected pipeline with fields to be active")
+	}
+}
+
+func TestTimestampedUntil(t *testing.T) {
+	p, err := NewPipeline([]string{"msg"}, "", nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Timestamped = true
+	p.Until = time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	out, ok := p.Process(`2026-01-01T09:59:59.123456789Z {"msg":"before"}`)
+	if !ok || out != "before" {
+		t.Errorf("Expected line before --until without timestamp, got %q (shown: %v)", out, ok)
+	}
+
+	if _, ok := p.Process(`2026-01-01T10:00:01Z {"msg":"after"}`); ok {
+		t.Error("Expected line after --until to be dropped")
+	}
+
+	p.ShowTimestamps = true
+	out, ok = p.Process(`2026-01-01T09:00:00Z {"msg":"kept"}`)
+	if !ok || out != "2026-01-01T09:00:00Z kept" {
+		t.Errorf("Expected timestamp to be kept, got %q", out)
+	}
+}
+
+func TestSplitTimestamp(t *testing.T) {
+	if _, rest, ok := SplitTimestamp("no timestamp here"); ok || rest != "no timestamp here" {
+		t.Errorf("Expected no timestamp, got rest %q (ok: %v)", rest, ok)
+	}
+
+	ts, rest, ok := SplitTimestamp("2026-01-01T10:00:00Z hello world")
+	if !ok || rest != "hello world" || ts.Hour() != 10 {
+		t.Errorf("Unexpected split: %v %q %v", ts, rest, ok)
+	}
+}
@@ -0,0 +1,107 @@
+// Package podexec runs a command in many pods with a concurrency limit and
+// groups the pods by identical results, so that the odd ones stand out.
+package podexec
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Result is the outcome of running the command in one pod. ExitCode is -1
+// when the command could not be run at all (Err is then set).
+type Result struct {
+	Pod      string
+	Output   string
+	ExitCode int
+	Err      error
+}
+
+// RunFunc runs the command in one pod
+type RunFunc func(ctx context.Context, pod string) Result
+
+// Run calls run for every pod with at most limit calls at a time and
+// returns the results in the order of pods. done, if set, is called after
+// every pod with the number of pods finished so far.
+func Run(ctx context.Context, pods []string, limit int, run RunFunc, done func(finished int)) []Result {
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]Result, len(pods))
+	slots := make(chan struct{}, limit)
+	var mu sync.Mutex
+	finished := 0
+
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		wg.Add(1)
+		go func(i int, pod string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = Result{Pod: pod, ExitCode: -1, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-slots }()
+
+			results[i] = run(ctx, pod)
+			results[i].Pod = pod
+
+			mu.Lock()
+			finished++
+			if done != nil {
+				done(finished)
+			}
+			mu.Unlock()
+		}(i, pod)
+	}
+	wg.Wait()
+	return results
+}
+
+// Group is a set of pods that returned the same output and exit code
+type Group struct {
+	Pods     []string
+	Output   string
+	ExitCode int
+}
+
+// GroupResults groups pods by identical output and exit code, largest
+// group first. The first group is the majority the others differ from.
+func GroupResults(results []Result) []Group {
+	type key struct {
+		output   string
+		exitCode int
+	}
+	index := map[key]int{}
+	var groups []Group
+	for _, r := range results {
+		k := key{output: r.Output, exitCode: r.ExitCode}
+		if r.Err != nil {
+			k.output = r.Err.Error()
+		}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, Group{Output: k.output, ExitCode: k.exitCode})
+		}
+		groups[i].Pods = append(groups[i].Pods, r.Pod)
+	}
+
+	for i := range groups {
+		sort.Strings(groups[i].Pods)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Pods) != len(groups[j].Pods) {
+			return len(groups[i].Pods) > len(groups[j].Pods)
+		}
+		if (groups[i].ExitCode == 0) != (groups[j].ExitCode == 0) {
+			return groups[i].ExitCode == 0
+		}
+		return groups[i].Pods[0] < groups[j].Pods[0]
+	})
+	return groups
+}
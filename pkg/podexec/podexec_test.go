@@ -0,0 +1,70 @@
+package podexec
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunLimitsConcurrency(t *testing.T) {
+	pods := []string{"web-1", "web-2", "web-3", "web-4", "web-5"}
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	run := func(_ context.Context, pod string) Result {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return Result{Output: "ok " + pod}
+	}
+
+	finished := 0
+	results := Run(context.Background(), pods, 2, run, func(n int) { finished = n })
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent runs, got %d", peak)
+	}
+	if finished != len(pods) {
+		t.Errorf("Expected progress to reach %d, got %d", len(pods), finished)
+	}
+	for i, r := range results {
+		if r.Pod != pods[i] || r.Output != "ok "+pods[i] {
+			t.Errorf("Result %d = %+v; expected the output of %s", i, r, pods[i])
+		}
+	}
+}
+
+func TestGroupResults(t *testing.T) {
+	results := []Result{
+		{Pod: "web-3", Output: "v1\n"},
+		{Pod: "web-1", Output: "v1\n"},
+		{Pod: "web-4", Output: "", ExitCode: 1},
+		{Pod: "web-2", Output: "v2\n"},
+		{Pod: "web-5", ExitCode: -1, Err: fmt.Errorf("pod not running")},
+	}
+
+	groups := GroupResults(results)
+	if len(groups) != 4 {
+		t.Fatalf("Expected 4 groups, got %+v", groups)
+	}
+	if len(groups[0].Pods) != 2 || groups[0].Pods[0] != "web-1" || groups[0].Output != "v1\n" {
+		t.Errorf("Expected the majority group web-1, web-3 first, got %+v", groups[0])
+	}
+	if groups[1].Pods[0] != "web-2" {
+		t.Errorf("Expected successful single pod groups before failed ones, got %+v", groups[1])
+	}
+	if groups[3].Output != "pod not running" || groups[3].ExitCode != -1 {
+		t.Errorf("Expected the error as output of the last group, got %+v", groups[3])
+	}
+}
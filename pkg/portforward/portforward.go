@@ -0,0 +1,237 @@
+// Package portforward parses port-forward targets and port mappings and
+// resolves them to a pod and its container ports, so that forwards to
+// services and workloads can follow their pods.
+package portforward
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+// Target kinds
+const (
+	KindPod         = "pod"
+	KindService     = "service"
+	KindDeployment  = "deployment"
+	KindStatefulSet = "statefulset"
+)
+
+// kindAliases maps the accepted resource type spellings to a kind
+var kindAliases = map[string]string{
+	"pod": KindPod, "pods": KindPod, "po": KindPod,
+	"service": KindService, "services": KindService, "svc": KindService,
+	"deployment": KindDeployment, "deployments": KindDeployment, "deploy": KindDeployment,
+	"statefulset": KindStatefulSet, "statefulsets": KindStatefulSet, "sts": KindStatefulSet,
+}
+
+// shortKinds are the prefixes used when printing targets
+var shortKinds = map[string]string{
+	KindPod: "pod", KindService: "svc", KindDeployment: "deploy", KindStatefulSet: "sts",
+}
+
+// Target is what a port-forward connects to
+type Target struct {
+	Kind string
+	Name string
+}
+
+// ParseTarget parses "name" (a pod), "svc/name", "deploy/name" or "sts/name"
+func ParseTarget(ref string) (Target, error) {
+	resourceType, name, found := strings.Cut(ref, "/")
+	if !found {
+		if ref == "" {
+			return Target{}, fmt.Errorf("target is required")
+		}
+		return Target{Kind: KindPod, Name: ref}, nil
+	}
+
+	kind, ok := kindAliases[resourceType]
+	if !ok {
+		return Target{}, fmt.Errorf("unsupported target type '%s' (use a pod name, svc/, deploy/ or sts/)", resourceType)
+	}
+	if name == "" {
+		return Target{}, fmt.Errorf("invalid target '%s', expected type/name (e.g. svc/web)", ref)
+	}
+	return Target{Kind: kind, Name: name}, nil
+}
+
+func (t Target) String() string {
+	return shortKinds[t.Kind] + "/" + t.Name
+}
+
+// Mapping is a requested forward from a local port to a remote port given
+// by number or by name. A zero local port asks for a free port to be picked.
+type Mapping struct {
+	Local  int
+	Remote string
+}
+
+// ParseMapping parses "local:remote", ":remote" (any free local port) or
+// "port" (the same port on both ends). The remote port may be a port name
+// ("8080:http").
+func ParseMapping(value string) (Mapping, error) {
+	localPart, remote, found := strings.Cut(value, ":")
+	if !found {
+		remote = localPart
+	}
+	if remote == "" {
+		return Mapping{}, fmt.Errorf("invalid port mapping '%s', expected local:remote (e.g. 8080:80)", value)
+	}
+
+	if !found {
+		local, err := parsePort(remote)
+		if err != nil {
+			return Mapping{}, fmt.Errorf("invalid port mapping '%s': a single port must be a number", value)
+		}
+		return Mapping{Local: local, Remote: remote}, nil
+	}
+
+	local := 0
+	if localPart != "" {
+		var err error
+		if local, err = parsePort(localPart); err != nil {
+			return Mapping{}, fmt.Errorf("invalid local port in '%s': %v", value, err)
+		}
+	}
+	if _, err := strconv.Atoi(remote); err == nil {
+		if _, err := parsePort(remote); err != nil {
+			return Mapping{}, fmt.Errorf("invalid remote port in '%s': %v", value, err)
+		}
+	}
+	return Mapping{Local: local, Remote: remote}, nil
+}
+
+// ParseMappings parses several mappings and rejects a local port requested
+// twice
+func ParseMappings(values []string) ([]Mapping, error) {
+	mappings := make([]Mapping, 0, len(values))
+	seen := map[int]bool{}
+	for _, value := range values {
+		mapping, err := ParseMapping(value)
+		if err != nil {
+			return nil, err
+		}
+		if mapping.Local != 0 {
+			if seen[mapping.Local] {
+				return nil, fmt.Errorf("local port %d is used by more than one mapping", mapping.Local)
+			}
+			seen[mapping.Local] = true
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// ParseAddresses parses the comma separated --address value. Each address
+// must be localhost or an IP address, as kubectl port-forward requires.
+func ParseAddresses(value string) ([]string, error) {
+	var addresses []string
+	for _, address := range strings.Split(value, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if address != "localhost" && net.ParseIP(address) == nil {
+			return nil, fmt.Errorf("invalid address '%s', expected localhost or an IP address", address)
+		}
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("at least one address is required")
+	}
+	return addresses, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a port number", value)
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port %d must be between 1 and 65535", port)
+	}
+	return port, nil
+}
+
+// SelectPod picks the pod to forward to: a ready running pod if there is
+// one, otherwise a running pod. Pods being deleted are never picked.
+func SelectPod(pods []kubernetes.Pod) (kubernetes.Pod, error) {
+	candidates := make([]kubernetes.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Status.Phase == "Running" && pod.Metadata.DeletionTimestamp == nil {
+			candidates = append(candidates, pod)
+		}
+	}
+	if len(candidates) == 0 {
+		return kubernetes.Pod{}, fmt.Errorf("no running pod found")
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].IsReady() != candidates[j].IsReady() {
+			return candidates[i].IsReady()
+		}
+		return candidates[i].Metadata.Name < candidates[j].Metadata.Name
+	})
+	return candidates[0], nil
+}
+
+// ContainerPort resolves a port number or name against the container ports
+// of a pod. It returns the port number and its name, if declared.
+func ContainerPort(pod kubernetes.Pod, port string) (int, string, error) {
+	if number, err := strconv.Atoi(port); err == nil {
+		for _, container := range pod.Spec.Containers {
+			for _, p := range container.Ports {
+				if p.ContainerPort == number {
+					return number, p.Name, nil
+				}
+			}
+		}
+		return number, "", nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == port {
+				return p.ContainerPort, p.Name, nil
+			}
+		}
+	}
+	return 0, "", fmt.Errorf("pod %s has no container port named '%s'", pod.Metadata.Name, port)
+}
+
+// ServicePort resolves a service port, by number or name, to the container
+// port it targets on a pod. Named target ports are looked up in the pod.
+func ServicePort(svc kubernetes.Service, pod kubernetes.Pod, port string) (int, string, error) {
+	for _, sp := range svc.Spec.Ports {
+		if sp.Name != port && strconv.Itoa(sp.Port) != port {
+			continue
+		}
+		switch {
+		case sp.TargetPort == nil:
+			return ContainerPort(pod, strconv.Itoa(sp.Port))
+		case sp.TargetPort.IsString:
+			return ContainerPort(pod, sp.TargetPort.StrVal)
+		default:
+			return ContainerPort(pod, strconv.Itoa(sp.TargetPort.IntVal))
+		}
+	}
+
+	var available []string
+	for _, sp := range svc.Spec.Ports {
+		available = append(available, ServicePortLabel(sp))
+	}
+	return 0, "", fmt.Errorf("service %s has no port '%s' (available: %s)", svc.Metadata.Name, port, strings.Join(available, ", "))
+}
+
+// ServicePortLabel renders a service port as "80/http"
+func ServicePortLabel(sp kubernetes.ServicePort) string {
+	if sp.Name == "" {
+		return strconv.Itoa(sp.Port)
+	}
+	return fmt.Sprintf("%d/%s", sp.Port, sp.Name)
+}
@@ -0,0 +1,158 @@
+package portforward
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stanzinofree/kcsi/pkg/kubernetes"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected Target
+	}{
+		{"web-1", Target{Kind: KindPod, Name: "web-1"}},
+		{"pod/web-1", Target{Kind: KindPod, Name: "web-1"}},
+		{"svc/web", Target{Kind: KindService, Name: "web"}},
+		{"deploy/web", Target{Kind: KindDeployment, Name: "web"}},
+		{"statefulset/db", Target{Kind: KindStatefulSet, Name: "db"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.ref)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseTarget(%q) = %+v, %v; expected %+v", tt.ref, got, err, tt.expected)
+		}
+	}
+
+	if target, _ := ParseTarget("service/web"); target.String() != "svc/web" {
+		t.Errorf("Expected svc/web, got %s", target)
+	}
+	for _, ref := range []string{"", "ds/agents", "svc/"} {
+		if _, err := ParseTarget(ref); err == nil {
+			t.Errorf("ParseTarget(%q) should fail", ref)
+		}
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Mapping
+	}{
+		{"8080:80", Mapping{Local: 8080, Remote: "80"}},
+		{"8080:http", Mapping{Local: 8080, Remote: "http"}},
+		{"5432", Mapping{Local: 5432, Remote: "5432"}},
+		{":http", Mapping{Local: 0, Remote: "http"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseMapping(tt.value)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseMapping(%q) = %+v, %v; expected %+v", tt.value, got, err, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"abc", "8080:", ":", "70000:80", "8080:0", "x:80"} {
+		if _, err := ParseMapping(value); err == nil {
+			t.Errorf("ParseMapping(%q) should fail", value)
+		}
+	}
+}
+
+func TestParseMappings(t *testing.T) {
+	mappings, err := ParseMappings([]string{"8080:http", ":9090", ":5432"})
+	if err != nil || len(mappings) != 3 {
+		t.Fatalf("Expected 3 mappings, got %+v (%v)", mappings, err)
+	}
+	if _, err := ParseMappings([]string{"8080:80", "8080:443"}); err == nil {
+		t.Errorf("Expected an error for a local port used twice")
+	}
+}
+
+func TestParseAddresses(t *testing.T) {
+	addresses, err := ParseAddresses("localhost, 10.0.0.5")
+	if err != nil || len(addresses) != 2 || addresses[1] != "10.0.0.5" {
+		t.Errorf("Unexpected addresses %v (%v)", addresses, err)
+	}
+	for _, value := range []string{"", "example.com", ","} {
+		if _, err := ParseAddresses(value); err == nil {
+			t.Errorf("ParseAddresses(%q) should fail", value)
+		}
+	}
+}
+
+func TestSelectPod(t *testing.T) {
+	var pods []kubernetes.Pod
+	data := `[
+		{"metadata": {"name": "web-a"}, "status": {"phase": "Pending"}},
+		{"metadata": {"name": "web-b"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "False"}]}},
+		{"metadata": {"name": "web-c", "deletionTimestamp": "2026-01-01T00:00:00Z"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"name": "web-d"}, "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "True"}]}}
+	]`
+	if err := json.Unmarshal([]byte(data), &pods); err != nil {
+		t.Fatalf("Failed to decode pods: %v", err)
+	}
+
+	pod, err := SelectPod(pods)
+	if err != nil || pod.Metadata.Name != "web-d" {
+		t.Errorf("Expected the ready pod web-d, got %s (%v)", pod.Metadata.Name, err)
+	}
+
+	pod, err = SelectPod(pods[:2])
+	if err != nil || pod.Metadata.Name != "web-b" {
+		t.Errorf("Expected the running pod web-b, got %s (%v)", pod.Metadata.Name, err)
+	}
+
+	if _, err := SelectPod(pods[:1]); err == nil {
+		t.Errorf("Expected an error without running pods")
+	}
+}
+
+func TestServicePort(t *testing.T) {
+	var pod kubernetes.Pod
+	data := `{"metadata": {"name": "web-1"}, "spec": {"containers": [
+		{"name": "app", "ports": [{"name": "web", "containerPort": 8080}, {"name": "metrics", "containerPort": 9091}]}
+	]}}`
+	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+		t.Fatalf("Failed to decode pod: %v", err)
+	}
+	var svc kubernetes.Service
+	data = `{"metadata": {"name": "web"}, "spec": {"ports": [
+		{"name": "http", "port": 80, "targetPort": "web"},
+		{"name": "metrics", "port": 9090, "targetPort": 9091},
+		{"port": 5432}
+	]}}`
+	if err := json.Unmarshal([]byte(data), &svc); err != nil {
+		t.Fatalf("Failed to decode service: %v", err)
+	}
+
+	tests := []struct {
+		port     string
+		expected int
+		name     string
+	}{
+		{"80", 8080, "web"},
+		{"http", 8080, "web"},
+		{"9090", 9091, "metrics"},
+		{"5432", 5432, ""},
+	}
+	for _, tt := range tests {
+		got, name, err := ServicePort(svc, pod, tt.port)
+		if err != nil || got != tt.expected || name != tt.name {
+			t.Errorf("ServicePort(%q) = %d %q %v; expected %d %q", tt.port, got, name, err, tt.expected, tt.name)
+		}
+	}
+
+	_, _, err := ServicePort(svc, pod, "8443")
+	if err == nil || !strings.Contains(err.Error(), "80/http") {
+		t.Errorf("Expected an error listing the service ports, got %v", err)
+	}
+
+	if port, _, err := ContainerPort(pod, "metrics"); err != nil || port != 9091 {
+		t.Errorf("Expected container port metrics to be 9091, got %d (%v)", port, err)
+	}
+	if _, _, err := ContainerPort(pod, "grpc"); err == nil {
+		t.Errorf("Expected an error for an unknown port name")
+	}
+}
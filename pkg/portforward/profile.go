@@ -0,0 +1,163 @@
+package portforward
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileSubdir is the directory under ~/.kcsi holding port-forward profiles
+const profileSubdir = "portforward"
+
+// profileNamePattern restricts profile names to safe file names
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Profile is a named group of port-forwards started together
+type Profile struct {
+	Name     string           `yaml:"name"`
+	Context  string           `yaml:"context,omitempty"`
+	Forwards []ProfileForward `yaml:"forwards"`
+}
+
+// ProfileForward is one target of a profile with its port mappings
+type ProfileForward struct {
+	Namespace string   `yaml:"namespace"`
+	Target    string   `yaml:"target"`
+	Mappings  []string `yaml:"mappings"`
+	Address   string   `yaml:"address,omitempty"`
+}
+
+// Label renders a forward as "namespace/svc/web"
+func (f ProfileForward) Label() string {
+	return f.Namespace + "/" + f.Target
+}
+
+// Validate checks the target, mappings and address of a forward
+func (f ProfileForward) Validate() error {
+	if f.Namespace == "" {
+		return fmt.Errorf("%s: namespace is required", f.Target)
+	}
+	if _, err := ParseTarget(f.Target); err != nil {
+		return fmt.Errorf("%s: %v", f.Label(), err)
+	}
+	if len(f.Mappings) == 0 {
+		return fmt.Errorf("%s: at least one port mapping is required", f.Label())
+	}
+	if _, err := ParseMappings(f.Mappings); err != nil {
+		return fmt.Errorf("%s: %v", f.Label(), err)
+	}
+	if f.Address != "" {
+		if _, err := ParseAddresses(f.Address); err != nil {
+			return fmt.Errorf("%s: %v", f.Label(), err)
+		}
+	}
+	return nil
+}
+
+// ValidateProfileName rejects names that are not usable as a file name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s' (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// ProfileDir returns the directory of the profiles of a context. Profiles
+// are kept per context since targets and namespaces differ across clusters.
+func ProfileDir(kcsiDir, contextName string) string {
+	if contextName == "" {
+		contextName = "default"
+	}
+	return filepath.Join(kcsiDir, profileSubdir, contextName)
+}
+
+// ProfilePath returns the file of a profile
+func ProfilePath(kcsiDir, contextName, name string) string {
+	return filepath.Join(ProfileDir(kcsiDir, contextName), name+".yaml")
+}
+
+// PIDPath returns the file recording the process running a profile
+func PIDPath(kcsiDir, contextName, name string) string {
+	return filepath.Join(ProfileDir(kcsiDir, contextName), name+".pid")
+}
+
+// LoadProfile reads a profile; it returns nil when there is none
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	var profile Profile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+	return &profile, nil
+}
+
+// Save writes the profile, creating its directory if needed
+func (p *Profile) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+// Put adds a forward to the profile, replacing the forward of the same
+// target in the same namespace. It reports whether a forward was replaced.
+func (p *Profile) Put(forward ProfileForward) bool {
+	for i, existing := range p.Forwards {
+		if existing.Namespace == forward.Namespace && sameTarget(existing.Target, forward.Target) {
+			p.Forwards[i] = forward
+			return true
+		}
+	}
+	p.Forwards = append(p.Forwards, forward)
+	return false
+}
+
+// sameTarget compares targets regardless of the type spelling (svc, service)
+func sameTarget(a, b string) bool {
+	ta, errA := ParseTarget(a)
+	tb, errB := ParseTarget(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ta == tb
+}
+
+// ListProfiles returns the profile names of a context, sorted
+func ListProfiles(kcsiDir, contextName string) ([]string, error) {
+	entries, err := os.ReadDir(ProfileDir(kcsiDir, contextName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
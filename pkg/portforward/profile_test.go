@@ -0,0 +1,71 @@
+package portforward
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := ProfilePath(dir, "", "morning")
+	if path != filepath.Join(dir, "portforward", "default", "morning.yaml") {
+		t.Errorf("Unexpected profile path %s", path)
+	}
+
+	if profile, err := LoadProfile(path); err != nil || profile != nil {
+		t.Fatalf("Expected no profile, got %+v (%v)", profile, err)
+	}
+
+	profile := &Profile{Name: "morning", Context: "prod"}
+	profile.Put(ProfileForward{Namespace: "shop", Target: "svc/web", Mappings: []string{"8080:http"}})
+	profile.Put(ProfileForward{Namespace: "db", Target: "sts/postgres", Mappings: []string{"5432"}})
+	if replaced := profile.Put(ProfileForward{Namespace: "shop", Target: "service/web", Mappings: []string{":http"}}); !replaced {
+		t.Errorf("Expected svc/web in shop to be replaced")
+	}
+	if err := profile.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadProfile(path)
+	if err != nil {
+		t.Fatalf("LoadProfile failed: %v", err)
+	}
+	if len(loaded.Forwards) != 2 || loaded.Forwards[0].Mappings[0] != ":http" {
+		t.Errorf("Unexpected profile %+v", loaded)
+	}
+
+	names, err := ListProfiles(dir, "")
+	if err != nil || len(names) != 1 || names[0] != "morning" {
+		t.Errorf("Expected [morning], got %v (%v)", names, err)
+	}
+}
+
+func TestProfileValidation(t *testing.T) {
+	valid := ProfileForward{Namespace: "shop", Target: "svc/web", Mappings: []string{"8080:http", ":metrics"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid forward, got %v", err)
+	}
+
+	invalid := []ProfileForward{
+		{Target: "svc/web", Mappings: []string{"8080"}},
+		{Namespace: "shop", Target: "ds/agents", Mappings: []string{"8080"}},
+		{Namespace: "shop", Target: "svc/web"},
+		{Namespace: "shop", Target: "svc/web", Mappings: []string{"8080"}, Address: "example.com"},
+	}
+	for _, forward := range invalid {
+		if err := forward.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", forward)
+		}
+	}
+
+	for _, name := range []string{"morning", "team-a_1.2"} {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("Expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../etc", "a/b", ".hidden"} {
+		if err := ValidateProfileName(name); err == nil {
+			t.Errorf("Expected %q to be invalid", name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package portforward
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff is an exponential delay between reconnect attempts
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// DefaultBackoff starts at one second and doubles up to 30 seconds
+var DefaultBackoff = Backoff{Initial: time.Second, Max: 30 * time.Second}
+
+// Delay returns the wait before the given consecutive attempt (starting at 1)
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// EventType is what happened to a supervised forward
+type EventType string
+
+// Event types
+const (
+	EventConnected EventType = "connected"
+	EventExited    EventType = "exited"
+)
+
+// Event reports a supervised forward connecting or exiting. For exits,
+// Pod is empty when the run failed before connecting and Delay is the wait
+// before the next attempt.
+type Event struct {
+	Time    time.Time
+	Type    EventType
+	Pod     string
+	Err     error
+	Attempt int
+	Delay   time.Duration
+}
+
+// RunFunc resolves the target and runs one port-forward until it exits or
+// ctx is done. It calls connected with the pod name once forwarding works.
+type RunFunc func(ctx context.Context, connected func(pod string)) error
+
+// StableAfter is how long a connection must last to reset the backoff
+const StableAfter = 10 * time.Second
+
+// Supervise runs a forward until ctx is done, restarting it with backoff
+// whenever it exits. Events are reported from the calling goroutine.
+func Supervise(ctx context.Context, run RunFunc, backoff Backoff, events func(Event)) {
+	attempt := 0
+	for ctx.Err() == nil {
+		pod := ""
+		var connectedAt time.Time
+		err := run(ctx, func(name string) {
+			pod = name
+			connectedAt = time.Now()
+			events(Event{Time: connectedAt, Type: EventConnected, Pod: name})
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !connectedAt.IsZero() && time.Since(connectedAt) >= StableAfter {
+			attempt = 0
+		}
+		attempt++
+		delay := backoff.Delay(attempt)
+		events(Event{Time: time.Now(), Type: EventExited, Pod: pod, Err: err, Attempt: attempt, Delay: delay})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{6, 30 * time.Second},
+		{50, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := DefaultBackoff.Delay(tt.attempt); got != tt.expected {
+			t.Errorf("Delay(%d) = %s; expected %s", tt.attempt, got, tt.expected)
+		}
+	}
+}
+
+func TestSupervise(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	run := func(ctx context.Context, connected func(string)) error {
+		runs++
+		if runs == 1 {
+			return fmt.Errorf("no running pod found")
+		}
+		connected(fmt.Sprintf("web-%d", runs))
+		if runs == 3 {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return fmt.Errorf("lost connection to pod")
+	}
+
+	var events []Event
+	Supervise(ctx, run, Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond}, func(e Event) {
+		events = append(events, e)
+	})
+
+	if runs != 3 {
+		t.Fatalf("Expected 3 runs, got %d", runs)
+	}
+	expected := []struct {
+		typ     EventType
+		pod     string
+		attempt int
+	}{
+		{EventExited, "", 1},
+		{EventConnected, "web-2", 0},
+		{EventExited, "web-2", 2},
+		{EventConnected, "web-3", 0},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for i, e := range expected {
+		if events[i].Type != e.typ || events[i].Pod != e.pod || events[i].Attempt != e.attempt {
+			t.Errorf("Event %d = %+v; expected %+v", i, events[i], e)
+		}
+	}
+	if events[2].Delay != 2*time.Millisecond {
+		t.Errorf("Expected the second retry to wait 2ms, got %s", events[2].Delay)
+	}
+}
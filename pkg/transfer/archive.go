@@ -0,0 +1,221 @@
+package transfer
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sums maps the paths of copied files, as named in the transfer ("app/
+// config.yaml", or the file name for a single file), to their SHA-256
+type Sums map[string]string
+
+// WriteTar writes a local file or directory as a tar stream whose root
+// entry is called name. Content is also written to progress. It returns
+// the checksums of the files and the paths skipped (symlinks, devices).
+func WriteTar(w io.Writer, localPath, name string, progress io.Writer) (Sums, []string, error) {
+	tw := tar.NewWriter(w)
+	sums := Sums{}
+	var skipped []string
+
+	err := filepath.WalkDir(localPath, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localPath, current)
+		if err != nil {
+			return err
+		}
+		entry := path.Join(name, filepath.ToSlash(rel))
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			skipped = append(skipped, entry)
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = entry
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+		if info.IsDir() {
+			header.Name += "/"
+			return tw.WriteHeader(header)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		file, err := os.Open(current)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		hash := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(tw, hash, progress), file); err != nil {
+			return err
+		}
+		sums[entry] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return sums, skipped, tw.Close()
+}
+
+// ExtractTar extracts a tar stream whose entries are rooted at fromName
+// into dest, renaming the root to toName. Entries outside the root or
+// escaping dest are rejected. Sums are keyed by the original entry names.
+func ExtractTar(r io.Reader, dest, fromName, toName string, progress io.Writer) (Sums, []string, error) {
+	tr := tar.NewReader(r)
+	sums := Sums{}
+	var skipped []string
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		target, err := LocalTarget(dest, name, fromName, toName)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, header.FileInfo().Mode().Perm()|0700); err != nil {
+				return nil, nil, err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, nil, err
+			}
+			sum, err := WriteFile(target, tr, header.FileInfo().Mode().Perm(), progress)
+			if err != nil {
+				return nil, nil, err
+			}
+			sums[name] = sum
+		default:
+			skipped = append(skipped, name)
+		}
+	}
+	return sums, skipped, nil
+}
+
+// LocalTarget maps a transferred path rooted at fromName to its local path
+// under dest, rooted at toName, refusing paths that escape dest
+func LocalTarget(dest, name, fromName, toName string) (string, error) {
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("refusing archive entry outside the destination: %s", name)
+	}
+	if name != fromName && !strings.HasPrefix(name, fromName+"/") {
+		return "", fmt.Errorf("unexpected archive entry: %s", name)
+	}
+
+	renamed := toName + strings.TrimPrefix(name, fromName)
+	target := filepath.Join(dest, filepath.FromSlash(renamed))
+	root := filepath.Clean(dest)
+	if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing archive entry outside the destination: %s", name)
+	}
+	return target, nil
+}
+
+// WriteFile writes r to a file, also copying the content to progress, and
+// returns its SHA-256
+func WriteFile(target string, r io.Reader, mode os.FileMode, progress io.Writer) (string, error) {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0600)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash, progress), r); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// LocalFiles lists the regular files of a local file or directory with
+// their path named as in the transfer, rooted at name
+func LocalFiles(localPath, name string) (dirs []string, files map[string]string, err error) {
+	files = map[string]string{}
+	err = filepath.WalkDir(localPath, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localPath, current)
+		if err != nil {
+			return err
+		}
+		entry := path.Join(name, filepath.ToSlash(rel))
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, entry)
+		case d.Type().IsRegular():
+			files[entry] = current
+		}
+		return nil
+	})
+	return dirs, files, err
+}
+
+// SumScript prints the SHA-256 of every file under $2, run from $1
+const SumScript = `cd "$1" && find "$2" -type f -exec sha256sum {} \;`
+
+// ParseSums parses sha256sum output
+func ParseSums(output string) Sums {
+	sums := Sums{}
+	for _, line := range strings.Split(output, "\n") {
+		sum, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || len(sum) != 64 {
+			continue
+		}
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		sums[path.Clean(strings.TrimPrefix(name, "./"))] = sum
+	}
+	return sums
+}
+
+// Verify compares the checksums of the copied files with those computed
+// in the container after the copy. A file whose container checksum differs
+// from before the copy was written to during or since the copy; it is
+// returned as changed rather than mismatched. before is nil for uploads.
+func Verify(copied, before, after Sums) (mismatched, changed []string) {
+	for name, sum := range copied {
+		switch {
+		case after[name] == sum:
+		case before != nil && before[name] != after[name]:
+			changed = append(changed, name)
+		default:
+			mismatched = append(mismatched, name)
+		}
+	}
+	sort.Strings(mismatched)
+	sort.Strings(changed)
+	return mismatched, changed
+}
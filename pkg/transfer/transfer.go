@@ -0,0 +1,148 @@
+// Package transfer copies files and directories between the local machine
+// and containers. It builds and extracts the tar streams, parses what the
+// container supports and verifies checksums; the commands themselves run
+// through kubectl exec.
+package transfer
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Location is one side of a copy: a local path, or a path in a pod
+type Location struct {
+	Namespace string
+	Pod       string
+	Path      string
+}
+
+// Remote reports whether the location is in a pod
+func (l Location) Remote() bool {
+	return l.Pod != ""
+}
+
+func (l Location) String() string {
+	if !l.Remote() {
+		return l.Path
+	}
+	return l.Pod + ":" + l.Path
+}
+
+// ParseLocation parses "pod:path", "namespace/pod:path" or a local path.
+// Paths starting with / or . and Windows drive paths are always local.
+func ParseLocation(arg string) (Location, error) {
+	if arg == "" {
+		return Location{}, fmt.Errorf("path is required")
+	}
+	if strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, ".") || isDrivePath(arg) {
+		return Location{Path: arg}, nil
+	}
+
+	pod, remotePath, found := strings.Cut(arg, ":")
+	if !found {
+		return Location{Path: arg}, nil
+	}
+	if remotePath == "" {
+		return Location{}, fmt.Errorf("invalid location '%s', expected pod:path", arg)
+	}
+
+	location := Location{Pod: pod, Path: remotePath}
+	if namespace, name, ok := strings.Cut(pod, "/"); ok {
+		if namespace == "" || name == "" {
+			return Location{}, fmt.Errorf("invalid pod '%s', expected namespace/pod", pod)
+		}
+		location.Namespace, location.Pod = namespace, name
+	}
+	return location, nil
+}
+
+// isDrivePath reports whether the argument is a Windows path like C:\dir
+func isDrivePath(arg string) bool {
+	return len(arg) >= 3 && arg[1] == ':' && (arg[2] == '\\' || arg[2] == '/') &&
+		(arg[0] >= 'a' && arg[0] <= 'z' || arg[0] >= 'A' && arg[0] <= 'Z')
+}
+
+// Path types reported by the probe
+const (
+	TypeFile    = "file"
+	TypeDir     = "dir"
+	TypeOther   = "other"
+	TypeMissing = "missing"
+)
+
+// ProbeScript inspects a path in a container and the tools available
+// there. It runs with sh -c and the path as $1.
+const ProbeScript = `p="$1"
+if [ -d "$p" ]; then echo type=dir; elif [ -f "$p" ]; then echo type=file; elif [ -e "$p" ]; then echo type=other; else echo type=missing; fi
+if [ -f "$p" ]; then echo size=$(wc -c < "$p"); elif [ -d "$p" ]; then echo size=$(( $(du -sk "$p" | cut -f1) * 1024 )); fi
+if [ -d "$(dirname "$p")" ]; then echo parent=dir; fi
+for t in tar base64 sha256sum find; do command -v $t >/dev/null 2>&1 && echo has=$t; done
+true`
+
+// Probe is what the probe script found
+type Probe struct {
+	Type string
+	// Size is the file size, or an approximation of the directory size
+	Size      int64
+	ParentDir bool
+	Tools     map[string]bool
+}
+
+// Has reports whether a tool is available in the container
+func (p Probe) Has(tool string) bool {
+	return p.Tools[tool]
+}
+
+// ParseProbe parses the output of ProbeScript
+func ParseProbe(output string) (Probe, error) {
+	probe := Probe{Tools: map[string]bool{}}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "type":
+			probe.Type = value
+		case "size":
+			probe.Size, _ = strconv.ParseInt(value, 10, 64)
+		case "parent":
+			probe.ParentDir = value == "dir"
+		case "has":
+			probe.Tools[value] = true
+		}
+	}
+	if probe.Type == "" {
+		return probe, fmt.Errorf("unexpected probe output: %s", strings.TrimSpace(output))
+	}
+	return probe, nil
+}
+
+// Split returns the parent directory and base name of a container path
+func Split(remotePath string) (string, string) {
+	cleaned := path.Clean(remotePath)
+	dir, base := path.Split(cleaned)
+	if dir == "" {
+		dir = "."
+	}
+	return path.Clean(dir), base
+}
+
+// Counter counts the bytes written through it, safe for concurrent reads
+type Counter struct {
+	n atomic.Int64
+}
+
+func (c *Counter) Write(p []byte) (int, error) {
+	c.n.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Bytes returns the number of bytes counted so far
+func (c *Counter) Bytes() int64 {
+	return c.n.Load()
+}
@@ -0,0 +1,127 @@
+package transfer
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected Location
+	}{
+		{"web-1:/etc/app", Location{Pod: "web-1", Path: "/etc/app"}},
+		{"shop/web-1:data", Location{Namespace: "shop", Pod: "web-1", Path: "data"}},
+		{"./web-1:backup", Location{Path: "./web-1:backup"}},
+		{"/tmp/a:b", Location{Path: "/tmp/a:b"}},
+		{`C:\Users\me`, Location{Path: `C:\Users\me`}},
+		{"notes.txt", Location{Path: "notes.txt"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLocation(tt.arg)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseLocation(%q) = %+v, %v; expected %+v", tt.arg, got, err, tt.expected)
+		}
+	}
+	for _, arg := range []string{"", "web-1:", "shop/:x"} {
+		if _, err := ParseLocation(arg); err == nil {
+			t.Errorf("ParseLocation(%q) should fail", arg)
+		}
+	}
+}
+
+func TestParseProbe(t *testing.T) {
+	probe, err := ParseProbe("type=dir\nsize=  8192\nparent=dir\nhas=tar\nhas=sha256sum\n")
+	if err != nil {
+		t.Fatalf("ParseProbe failed: %v", err)
+	}
+	if probe.Type != TypeDir || probe.Size != 8192 || !probe.ParentDir || !probe.Has("tar") || probe.Has("base64") {
+		t.Errorf("Unexpected probe %+v", probe)
+	}
+	if _, err := ParseProbe("sh: not found"); err == nil {
+		t.Errorf("Expected an error without a type")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	if dir, base := Split("/etc/app/"); dir != "/etc" || base != "app" {
+		t.Errorf("Split(/etc/app/) = %s %s", dir, base)
+	}
+	if dir, base := Split("config.yaml"); dir != "." || base != "config.yaml" {
+		t.Errorf("Split(config.yaml) = %s %s", dir, base)
+	}
+}
+
+func TestTarRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "conf", "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "conf", "app.yaml"), []byte("key: v1\n"), 0644)
+	os.WriteFile(filepath.Join(src, "conf", "sub", "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.Symlink("app.yaml", filepath.Join(src, "conf", "link"))
+
+	var archive bytes.Buffer
+	var written Counter
+	sums, skipped, err := WriteTar(&archive, filepath.Join(src, "conf"), "conf", &written)
+	if err != nil {
+		t.Fatalf("WriteTar failed: %v", err)
+	}
+	if len(sums) != 2 || !reflect.DeepEqual(skipped, []string{"conf/link"}) || written.Bytes() != 18 {
+		t.Errorf("Unexpected sums %v, skipped %v, bytes %d", sums, skipped, written.Bytes())
+	}
+
+	dest := t.TempDir()
+	extracted, _, err := ExtractTar(&archive, dest, "conf", "config", io.Discard)
+	if err != nil {
+		t.Fatalf("ExtractTar failed: %v", err)
+	}
+	if mismatched, _ := Verify(sums, nil, extracted); len(mismatched) != 0 {
+		t.Errorf("Expected identical checksums, mismatched %v", mismatched)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "config", "app.yaml"))
+	if err != nil || string(data) != "key: v1\n" {
+		t.Errorf("Expected the renamed root to hold app.yaml, got %q (%v)", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "config", "sub", "run.sh")); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected run.sh to stay executable (%v)", err)
+	}
+}
+
+func TestExtractTarRejectsEscapes(t *testing.T) {
+	for _, name := range []string{"../evil", "/etc/passwd", "other/file", "conf/../../evil"} {
+		var archive bytes.Buffer
+		tw := tar.NewWriter(&archive)
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+		tw.Write([]byte("x"))
+		tw.Close()
+
+		if _, _, err := ExtractTar(&archive, t.TempDir(), "conf", "conf", io.Discard); err == nil {
+			t.Errorf("Expected entry %q to be rejected", name)
+		}
+	}
+}
+
+func TestParseSumsAndVerify(t *testing.T) {
+	a := "0000000000000000000000000000000000000000000000000000000000000000"
+	b := "1111111111111111111111111111111111111111111111111111111111111111"
+	remote := ParseSums(a + "  ./conf/app.yaml\n" + b + " *conf/run.sh\nsha256sum: x: Permission denied\n")
+	if remote["conf/app.yaml"] != a || remote["conf/run.sh"] != b {
+		t.Errorf("Unexpected sums %v", remote)
+	}
+
+	local := Sums{"conf/app.yaml": a, "conf/run.sh": a, "conf/new": b}
+	if mismatched, changed := Verify(local, nil, remote); !reflect.DeepEqual(mismatched, []string{"conf/new", "conf/run.sh"}) || changed != nil {
+		t.Errorf("Unexpected mismatches %v, changed %v", mismatched, changed)
+	}
+
+	// run.sh was rewritten in the container while it was copied
+	before := Sums{"conf/app.yaml": a, "conf/run.sh": a}
+	mismatched, changed := Verify(local, before, remote)
+	if !reflect.DeepEqual(mismatched, []string{"conf/new"}) || !reflect.DeepEqual(changed, []string{"conf/run.sh"}) {
+		t.Errorf("Unexpected mismatches %v, changed %v", mismatched, changed)
+	}
+}